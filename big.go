@@ -0,0 +1,253 @@
+package suss
+
+import (
+	"math/big"
+	"math/rand"
+)
+
+// defaultBigBits is the size of the magnitudes drawn
+// by the big generators when no size is given.
+const defaultBigBits = 256
+
+// bigPlan guides the generation of a big magnitude towards
+// one of the nasty values. The plan is made the first time
+// a byte is sampled, so that magnitudes read from an existing
+// buffer don't pay for it.
+type bigPlan struct {
+	maxBits int
+	planned bool
+	digits  []byte
+	pos     int
+}
+
+func (p *bigPlan) plan(r *rand.Rand) {
+	if p.planned {
+		return
+	}
+	p.planned = true
+	if r.Intn(2) == 0 {
+		return
+	}
+	nasty := nastyBigs(p.maxBits)
+	if len(nasty) == 0 {
+		return
+	}
+	p.digits = nasty[r.Intn(len(nasty))].Bytes()
+}
+
+// more samples a continuation byte
+func (p *bigPlan) more(r *rand.Rand, n int) []byte {
+	p.plan(r)
+	if p.digits == nil {
+		// same length distribution as a slice with avg 8
+		if r.Intn(9) == 0 {
+			return []byte{0}
+		}
+		return []byte{1}
+	}
+	if p.pos < len(p.digits) {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// digit samples a digit
+func (p *bigPlan) digit(r *rand.Rand, n int) []byte {
+	p.plan(r)
+	if p.digits == nil || p.pos >= len(p.digits) {
+		return []byte{byte(r.Intn(256))}
+	}
+	b := p.digits[p.pos]
+	p.pos++
+	return []byte{b}
+}
+
+// nastyBigs returns the values just around powers of two
+// that are word sizes, or the size of common types,
+// and around powers of ten, for decimal code.
+// Only values that fit in maxBits are returned.
+func nastyBigs(maxBits int) []*big.Int {
+	var nasty []*big.Int
+	one := big.NewInt(1)
+	add := func(p *big.Int) {
+		for _, v := range []*big.Int{
+			new(big.Int).Sub(p, one),
+			p,
+			new(big.Int).Add(p, one),
+		} {
+			if v.BitLen() <= maxBits {
+				nasty = append(nasty, v)
+			}
+		}
+	}
+	for _, k := range []uint{7, 8, 15, 16, 31, 32, 52, 53, 63, 64, 127, 128, 255, 256} {
+		add(new(big.Int).Lsh(one, k))
+	}
+	ten := big.NewInt(10)
+	for _, k := range []int64{2, 4, 9, 18, 19, 38} {
+		add(new(big.Int).Exp(ten, big.NewInt(k), nil))
+	}
+	return nasty
+}
+
+// drawBigNat draws a natural number of at most maxBits bits.
+//
+// The magnitude is a sequence of base 256 digits, most significant
+// first, with a continuation byte in front of each digit, the same
+// way that slices are drawn. Deleting a digit from the buffer
+// divides the value, and the first digits are the most significant,
+// so that lexicographically smaller buffers are smaller numbers.
+func drawBigNat(d Data, maxBits int) *big.Int {
+	if maxBits <= 0 {
+		panic("invalid big number size")
+	}
+	p := &bigPlan{maxBits: maxBits}
+	maxDigits := (maxBits + 7) / 8
+	var digits []byte
+	for len(digits) < maxDigits {
+		d.StartExample()
+		more := d.Draw(1, p.more)[0] != 0
+		if !more {
+			d.EndExample()
+			break
+		}
+		digits = append(digits, d.Draw(1, p.digit)[0])
+		d.EndExample()
+	}
+	v := new(big.Int).SetBytes(digits)
+	if v.BitLen() > maxBits {
+		// the top digit has bits to spare
+		mask := new(big.Int).Lsh(big.NewInt(1), uint(maxBits))
+		v.Mod(v, mask)
+	}
+	return v
+}
+
+// drawBigInt draws an integer with a magnitude of
+// at most maxBits bits. Positive numbers are simpler.
+func drawBigInt(d Data, maxBits int) *big.Int {
+	neg := drawIndex(d, 2, nil) == 1
+	v := drawBigNat(d, maxBits)
+	if neg {
+		v.Neg(v)
+	}
+	return v
+}
+
+// BigIntGen generates *big.Int values with a magnitude of
+// at most MaxBits bits, 256 if MaxBits is 0.
+// Values shrink towards 0, by removing digits
+// and then making the remaining ones smaller.
+// After Fill, the value can be read from Value.
+type BigIntGen struct {
+	Value   *big.Int
+	MaxBits int
+	// NonNegative keeps values from being negative.
+	NonNegative bool
+}
+
+func (g *BigIntGen) Fill(d Data) {
+	bits := g.MaxBits
+	if bits == 0 {
+		bits = defaultBigBits
+	}
+	if g.NonNegative {
+		g.Value = drawBigNat(d, bits)
+	} else {
+		g.Value = drawBigInt(d, bits)
+	}
+}
+
+// BigInt is a convenience function that returns
+// a *big.Int from the Runner.
+func (r *Runner) BigInt() *big.Int {
+	var g BigIntGen
+	r.Draw(&g)
+	return g.Value
+}
+
+// BigRatGen generates *big.Rat values whose numerator and
+// denominator have at most MaxBits bits, 256 if MaxBits is 0.
+// Values shrink towards 0 and towards integers.
+// After Fill, the value can be read from Value.
+type BigRatGen struct {
+	Value   *big.Rat
+	MaxBits int
+}
+
+func (g *BigRatGen) Fill(d Data) {
+	bits := g.MaxBits
+	if bits == 0 {
+		bits = defaultBigBits
+	}
+	num := drawBigInt(d, bits)
+	// a zero denominator is made 1, so that a
+	// zero in the buffer makes an integer
+	denom := drawBigNat(d, bits)
+	if denom.Sign() == 0 {
+		denom.SetInt64(1)
+	}
+	g.Value = new(big.Rat).SetFrac(num, denom)
+}
+
+// BigRat is a convenience function that returns
+// a *big.Rat from the Runner.
+func (r *Runner) BigRat() *big.Rat {
+	var g BigRatGen
+	r.Draw(&g)
+	return g.Value
+}
+
+// bigModes are the rounding modes a big.Float can have,
+// in the order they shrink towards.
+var bigModes = []big.RoundingMode{
+	big.ToNearestEven,
+	big.ToNearestAway,
+	big.ToZero,
+	big.AwayFromZero,
+	big.ToNegativeInf,
+	big.ToPositiveInf,
+}
+
+// BigFloatGen generates finite *big.Float values with a precision
+// of Prec bits, 53 if Prec is 0, and binary exponents, as
+// returned by MantExp, in [-MaxExp, MaxExp], 1024 if MaxExp is 0.
+// The rounding mode of the value is drawn too, since it
+// affects all the arithmetic done with it.
+//
+// Values shrink towards 0, towards integers and
+// towards the ToNearestEven rounding mode.
+// After Fill, the value can be read from Value.
+type BigFloatGen struct {
+	Value  *big.Float
+	Prec   uint
+	MaxExp int
+}
+
+func (g *BigFloatGen) Fill(d Data) {
+	prec := g.Prec
+	if prec == 0 {
+		prec = 53
+	}
+	maxExp := int64(g.MaxExp)
+	if maxExp == 0 {
+		maxExp = 1024
+	}
+	mode := bigModes[drawIndex(d, uint64(len(bigModes)), nil)]
+	mant := drawBigInt(d, int(prec))
+	// the value is mant * 2**exp, so the exponent
+	// of the value is exp plus the length of mant
+	mbits := int64(mant.BitLen())
+	exp := drawInt(d, -maxExp-mbits, maxExp-mbits)
+	f := new(big.Float).SetPrec(prec).SetMode(mode)
+	f.SetInt(mant)
+	g.Value = f.SetMantExp(f, int(exp))
+}
+
+// BigFloat is a convenience function that returns
+// a *big.Float from the Runner.
+func (r *Runner) BigFloat() *big.Float {
+	var g BigFloatGen
+	r.Draw(&g)
+	return g.Value
+}
@@ -0,0 +1,65 @@
+package suss
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigIntBits(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		bits := s.IntRange(1, 300)
+		g := BigIntGen{MaxBits: bits, NonNegative: s.Bool()}
+		s.Draw(&g)
+		if g.Value.BitLen() > bits {
+			s.Fatalf("%v has more than %d bits", g.Value, bits)
+		}
+		if g.NonNegative && g.Value.Sign() < 0 {
+			s.Fatalf("negative value %v", g.Value)
+		}
+	})
+}
+
+func TestBigSimplest(t *testing.T) {
+	var ig BigIntGen
+	ig.Fill(bufFromBytes(make([]byte, 10)))
+	if ig.Value.Sign() != 0 {
+		t.Errorf("simplest int = %v, want 0", ig.Value)
+	}
+	var rg BigRatGen
+	rg.Fill(bufFromBytes(make([]byte, 10)))
+	if rg.Value.Sign() != 0 || !rg.Value.IsInt() {
+		t.Errorf("simplest rat = %v, want 0", rg.Value)
+	}
+	var fg BigFloatGen
+	fg.Fill(bufFromBytes(make([]byte, 20)))
+	if fg.Value.Sign() != 0 || fg.Value.Mode() != big.ToNearestEven {
+		t.Errorf("simplest float = %v (%v), want 0", fg.Value, fg.Value.Mode())
+	}
+}
+
+func TestBigIntDigits(t *testing.T) {
+	// sign, then a continuation byte before every digit
+	var g BigIntGen
+	g.Fill(bufFromBytes([]byte{1, 1, 0x01, 1, 0x02, 0}))
+	if g.Value.Int64() != -0x0102 {
+		t.Errorf("value = %v, want %v", g.Value, -0x0102)
+	}
+}
+
+func TestBigBounds(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		bits := s.IntRange(1, 100)
+		rg := BigRatGen{MaxBits: bits}
+		s.Draw(&rg)
+		if rg.Value.Num().BitLen() > bits || rg.Value.Denom().BitLen() > bits {
+			s.Fatalf("%v has more than %d bits", rg.Value, bits)
+		}
+		fg := BigFloatGen{Prec: uint(s.IntRange(1, 100)), MaxExp: s.IntRange(1, 100)}
+		s.Draw(&fg)
+		if exp := fg.Value.MantExp(nil); exp < -fg.MaxExp || exp > fg.MaxExp {
+			s.Fatalf("%v has exponent %d, outside [%d, %d]", fg.Value, exp, -fg.MaxExp, fg.MaxExp)
+		}
+	})
+}
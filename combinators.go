@@ -0,0 +1,150 @@
+package suss
+
+import (
+	"math"
+	"math/rand"
+)
+
+// filterRetries is the number of times Filter draws a value
+// before giving up and marking the data invalid.
+const filterRetries = 3
+
+// Map returns a generator that applies f to the values drawn from g.
+// Values shrink as the values drawn from g shrink.
+func Map[T, U any](g Gen[T], f func(T) U) Gen[U] {
+	return GenFunc[U](func(d Data) U {
+		return f(DrawFrom(d, g))
+	})
+}
+
+// Filter returns a generator for the values drawn from g
+// that satisfy pred. Values are redrawn a few times if they
+// don't satisfy pred, after that the data is marked invalid.
+//
+// Filter should only be used when most values satisfy pred.
+// Generating valid values directly is much more efficient.
+func Filter[T any](g Gen[T], pred func(T) bool) Gen[T] {
+	return GenFunc[T](func(d Data) T {
+		for i := 0; i < filterRetries; i++ {
+			v := DrawFrom(d, g)
+			if pred(v) {
+				return v
+			}
+		}
+		Invalid()
+		panic("unreachable")
+	})
+}
+
+// FlatMap returns a generator for dependent values. It draws
+// a value from g, and then draws from the generator f returns
+// for that value.
+//
+//	// a slice and a valid index into it
+//	g := suss.FlatMap(slices, func(s []int) suss.Gen[int] {
+//		return suss.SampledFrom(indices(s)...)
+//	})
+func FlatMap[T, U any](g Gen[T], f func(T) Gen[U]) Gen[U] {
+	return GenFunc[U](func(d Data) U {
+		return DrawFrom(d, f(DrawFrom(d, g)))
+	})
+}
+
+// OneOf returns a generator that draws from one of gens.
+// It shrinks towards drawing from the first generator.
+func OneOf[T any](gens ...Gen[T]) Gen[T] {
+	if len(gens) == 0 {
+		panic("suss: OneOf needs at least one generator")
+	}
+	gens = append([]Gen[T](nil), gens...)
+	return GenFunc[T](func(d Data) T {
+		i := drawIndex(d, uint64(len(gens)), nil)
+		return DrawFrom(d, gens[i])
+	})
+}
+
+// Weighted is a generator with a weight, for Frequency.
+type Weighted[T any] struct {
+	Weight float64
+	Gen    Gen[T]
+}
+
+// Frequency returns a generator that draws from one of the
+// generators in choices, picking each with a probability
+// proportional to its weight.
+//
+//	op := suss.Frequency([]suss.Weighted[string]{
+//		{80, suss.Just("read")},
+//		{20, suss.Just("write")},
+//	})
+//
+// The weights only affect generation. Regardless of the
+// weights, it shrinks towards drawing from the first generator.
+func Frequency[T any](choices []Weighted[T]) Gen[T] {
+	if len(choices) == 0 {
+		panic("suss: Frequency needs at least one choice")
+	}
+	choices = append([]Weighted[T](nil), choices...)
+	total := 0.0
+	for _, c := range choices {
+		if c.Weight < 0 || math.IsNaN(c.Weight) || math.IsInf(c.Weight, 0) {
+			panic("suss: invalid weight")
+		}
+		total += c.Weight
+	}
+	if total == 0 {
+		panic("suss: Frequency needs a positive weight")
+	}
+	smp := func(r *rand.Rand) uint64 {
+		roll := r.Float64() * total
+		for i, c := range choices {
+			if roll < c.Weight {
+				return uint64(i)
+			}
+			roll -= c.Weight
+		}
+		// rounding error, pick the last choice
+		// that can be picked
+		i := len(choices) - 1
+		for choices[i].Weight == 0 {
+			i--
+		}
+		return uint64(i)
+	}
+	return GenFunc[T](func(d Data) T {
+		i := drawIndex(d, uint64(len(choices)), smp)
+		return DrawFrom(d, choices[i].Gen)
+	})
+}
+
+// Weighted returns true with probability p.
+// It shrinks towards false.
+func (r *Runner) Weighted(p float64) bool {
+	if !(p >= 0 && p <= 1) {
+		panic("suss: invalid probability")
+	}
+	r.buf.StartExample()
+	b := biasBool(r.buf, p)
+	r.buf.EndExample()
+	return b
+}
+
+// Just returns a generator that always returns v.
+// It draws no data.
+func Just[T any](v T) Gen[T] {
+	return GenFunc[T](func(d Data) T {
+		return v
+	})
+}
+
+// SampledFrom returns a generator that returns one of values.
+// It shrinks towards the first value.
+func SampledFrom[T any](values ...T) Gen[T] {
+	if len(values) == 0 {
+		panic("suss: SampledFrom needs at least one value")
+	}
+	values = append([]T(nil), values...)
+	return GenFunc[T](func(d Data) T {
+		return values[drawIndex(d, uint64(len(values)), nil)]
+	})
+}
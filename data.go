@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"sort"
+	"time"
 )
 
 type buffer struct {
@@ -26,6 +27,12 @@ type buffer struct {
 	hitNovelty bool
 	finalized  bool
 	stdout     string
+	stdoutMem  bytes.Buffer
+	log        bytes.Buffer
+	targets    map[string]float64
+	events     map[string]bool
+	// time spent drawing bytes, for HealthSlow
+	drawTime time.Duration
 
 	sortedInter [][2]int
 }
@@ -59,7 +66,9 @@ func (b *buffer) Draw(n int, smp Sample) []byte {
 		b.overdraw = (b.index + n) - b.maxLength
 		panic(new(eos))
 	}
+	start := time.Now()
 	byt := b.drawf(b, n, smp)
+	b.drawTime += time.Since(start)
 	b.blocks = append(b.blocks, [2]int{initial, initial + n})
 	b.buf = append(b.buf, byt...)
 	b.index += n
@@ -0,0 +1,116 @@
+package suss
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// exampleDB is a directory of previously found failing examples.
+// Every file in the directory holds the bytes of a shrunk buffer
+// and is named after the hash of its contents, so saving the same
+// example twice is harmless.
+//
+// Examples are replayed before any random generation starts. That
+// way, a bug found once will keep failing the test until it is fixed.
+type exampleDB struct {
+	dir string
+}
+
+// newExampleDB returns the database for the test with the given
+// name. Subtests get a subdirectory of their parent test.
+func newExampleDB(name string) *exampleDB {
+	return &exampleDB{
+		dir: filepath.Join("testdata", "suss", filepath.FromSlash(sanitizeName(name))),
+	}
+}
+
+// sanitizeName makes a test name safe to use as a path.
+// Anything that isn't a letter, digit, '-', '_' or a subtest
+// separator is replaced with an underscore.
+func sanitizeName(name string) string {
+	mapping := func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			return c
+		case c == '-' || c == '_' || c == '/':
+			return c
+		}
+		return '_'
+	}
+	name = strings.Map(mapping, name)
+	// empty subtest names would otherwise collapse into their parent
+	elems := strings.Split(name, "/")
+	for i, e := range elems {
+		if e == "" {
+			elems[i] = "_"
+		}
+	}
+	return strings.Join(elems, "/")
+}
+
+func (db *exampleDB) key(byt []byte) string {
+	sum := sha1.Sum(byt)
+	return hex.EncodeToString(sum[:])
+}
+
+// fetch returns all the examples stored in the database.
+// The examples are sorted so that the simplest examples
+// are replayed first.
+func (db *exampleDB) fetch() ([][]byte, error) {
+	infos, err := ioutil.ReadDir(db.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var examples [][]byte
+	for _, fi := range infos {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		byt, err := ioutil.ReadFile(filepath.Join(db.dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		examples = append(examples, byt)
+	}
+	sort.Slice(examples, func(i, j int) bool {
+		return shortlex(examples[i], examples[j])
+	})
+	return examples, nil
+}
+
+// save stores an example in the database.
+func (db *exampleDB) save(byt []byte) error {
+	err := os.MkdirAll(db.dir, 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(db.dir, db.key(byt)), byt, 0644)
+}
+
+// delete removes an example from the database.
+// Deleting an example that isn't in the database is not an error.
+func (db *exampleDB) delete(byt []byte) error {
+	err := os.Remove(filepath.Join(db.dir, db.key(byt)))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// shortlex reports whether a is simpler than b.
+// Shorter buffers are simpler, buffers of equal length
+// are compared lexicographically.
+func shortlex(a, b []byte) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return string(a) < string(b)
+}
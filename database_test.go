@@ -0,0 +1,115 @@
+package suss
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExampleDB(t *testing.T) {
+	db := &exampleDB{dir: filepath.Join(t.TempDir(), "db")}
+	examples, err := db.fetch()
+	if err != nil || len(examples) != 0 {
+		t.Fatalf("fetch on missing dir = %v, %v; want no examples", examples, err)
+	}
+	long := []byte{0, 1, 2}
+	short := []byte{5}
+	for _, b := range [][]byte{long, short, short} {
+		if err := db.save(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	examples, err = db.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 2 || !bytes.Equal(examples[0], short) || !bytes.Equal(examples[1], long) {
+		t.Fatalf("fetch = %v; want [%v %v]", examples, short, long)
+	}
+	if err := db.delete(short); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.delete(short); err != nil {
+		t.Fatalf("deleting missing example: %v", err)
+	}
+	examples, _ = db.fetch()
+	if len(examples) != 1 || !bytes.Equal(examples[0], long) {
+		t.Fatalf("fetch after delete = %v; want [%v]", examples, long)
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"TestFoo", "TestFoo"},
+		{"TestFoo/sub_test#01", "TestFoo/sub_test_01"},
+		{"TestFoo/../x", "TestFoo/__/x"},
+		{"TestFoo//x", "TestFoo/_/x"},
+	}
+	for _, tt := range tests {
+		if got := sanitizeName(tt.in); got != tt.out {
+			t.Errorf("sanitizeName(%q) = %q; want %q", tt.in, got, tt.out)
+		}
+	}
+}
+
+func TestRunSavesExample(t *testing.T) {
+	dir := os.Getenv("SUSS_TEST_DB")
+	if !inSubprocess() {
+		dir = t.TempDir()
+		out, failed := runSubprocess(t, "SUSS_TEST_DB="+dir)
+		if !failed {
+			t.Fatalf("test did not fail:\n%s", out)
+		}
+		examples, err := (&exampleDB{dir: dir}).fetch()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the shrunk example is the single byte 10
+		if len(examples) != 1 || !bytes.Equal(examples[0], []byte{10}) {
+			t.Fatalf("saved examples = %v, want [[10]]", examples)
+		}
+		return
+	}
+	s := NewTest(t)
+	s.db = &exampleDB{dir: dir}
+	s.Run(func() {
+		if s.Byte() >= 10 {
+			s.Fatalf("too big")
+		}
+	})
+}
+
+func TestRunReplaysExamples(t *testing.T) {
+	db := &exampleDB{dir: t.TempDir()}
+	// an example that no longer fails, and one that
+	// draws less data than the test does now
+	for _, byt := range [][]byte{{42}, {7, 8}} {
+		if err := db.save(byt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var drawn [][2]byte
+	s := NewTest(t).WithSettings(Settings{MaxExamples: 10})
+	s.db = db
+	s.Run(func() {
+		drawn = append(drawn, [2]byte{s.Byte(), s.Byte()})
+		s.Byte()
+	})
+	// the stored examples run first, simplest first, and
+	// again with zeros after them when they are too short.
+	// {42} is too short for the first two draws.
+	want := [][2]byte{{42, 0}, {7, 8}, {7, 8}}
+	if len(drawn) < 3 || drawn[0] != want[0] || drawn[1] != want[1] || drawn[2] != want[2] {
+		t.Fatalf("first examples = %v, want the stored examples", drawn)
+	}
+	examples, err := db.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 0 {
+		t.Fatalf("passing examples %v were not evicted", examples)
+	}
+}
@@ -1,16 +1,18 @@
 package suss
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"math"
+	"math/bits"
 	"math/rand"
 
 	"testing"
 )
 
 func TestEncFloat64(t *testing.T) {
-	s := NewTest(t)
+	s := newTest(t)
 	s.Run(func() {
 		sign := s.Bool()
 		signb := uint64(0)
@@ -60,3 +62,174 @@ func (e *exponent) Fill(d Data) {
 	}
 	*e = exponent(exp)
 }
+
+// TestDecFloat64Canonical checks that every byte sequence that
+// decodes to a float is the encoding of that float, so that no
+// float has more than one encoding.
+func TestDecFloat64Canonical(t *testing.T) {
+	s := newTest(t)
+	s.Run(func() {
+		var b [10]byte
+		copy(b[:], s.buf.Draw(10, func(r *rand.Rand, n int) []byte {
+			b := encodefloat64(math.Float64frombits(r.Uint64()))
+			// perturb the encoding to find
+			// non-canonical sequences
+			b[r.Intn(len(b))] ^= 1 << uint(r.Intn(8))
+			return b[:]
+		}))
+		f, invalid := decodefloat64(b[:])
+		if invalid {
+			return
+		}
+		if enc := encodefloat64(f); enc != b {
+			s.Fatalf("%x decodes to %v, which encodes as %x", b, f, enc)
+		}
+	})
+}
+
+// TestEncFloat64Order checks that the ordering of encodings
+// is total and follows the intuition of which floats are simpler.
+func TestEncFloat64Order(t *testing.T) {
+	s := newTest(t)
+	s.Run(func() {
+		a := drawOrderFloat(s)
+		b := drawOrderFloat(s)
+		ea, eb := encodefloat64(a), encodefloat64(b)
+		abits, bbits := math.Float64bits(a), math.Float64bits(b)
+		if (ea == eb) != (abits == bbits) {
+			s.Fatalf("%v and %v have the same encoding %x", a, b, ea)
+		}
+		if abits == bbits {
+			return
+		}
+		if bytes.Compare(ea[:], eb[:]) > 0 {
+			a, b = b, a
+			abits, bbits = bbits, abits
+		}
+		// a is simpler than b, check that it makes sense
+		asign, bsign := abits>>63, bbits>>63
+		if asign != bsign {
+			if asign == 1 {
+				s.Fatalf("negative %v simpler than positive %v", a, b)
+			}
+			return
+		}
+		rank := func(f float64) int {
+			switch {
+			case math.IsNaN(f) || math.IsInf(f, 0):
+				return 3
+			case isSubnormal(f):
+				return 2
+			case math.Abs(f) < 1<<64 && f == math.Trunc(f):
+				return 0
+			}
+			return 1
+		}
+		ra, rb := rank(a), rank(b)
+		if ra > rb {
+			s.Fatalf("%v (class %v) simpler than %v (class %v)", a, ra, b, rb)
+		}
+		if ra == 0 && rb == 0 && math.Abs(a) > math.Abs(b) {
+			s.Fatalf("integer %v simpler than smaller integer %v", a, b)
+		}
+		if ra == 1 && rb == 1 {
+			_, aexp := math.Frexp(a)
+			_, bexp := math.Frexp(b)
+			// Frexp returns exponents one larger than IEEE 754
+			aexp, bexp = aexp-1, bexp-1
+			if aexp == bexp && significantBits(a) > significantBits(b) {
+				s.Fatalf("%v simpler than %v, which has fewer significant bits", a, b)
+			}
+			if (aexp < 0) == (bexp < 0) && abs(aexp) > abs(bexp) {
+				s.Fatalf("%v simpler than %v, which has smaller exponent", a, b)
+			}
+		}
+	})
+}
+
+func drawOrderFloat(s *Runner) float64 {
+	if s.Bool() {
+		return s.Float64()
+	}
+	return math.Float64frombits(s.Uint64())
+}
+
+func significantBits(f float64) int {
+	mant := math.Float64bits(f) & mantMask
+	if mant == 0 {
+		return 0
+	}
+	return 52 - bits.TrailingZeros64(mant)
+}
+
+func abs(i int) int {
+	if i < 0 {
+		return -i
+	}
+	return i
+}
+
+func TestDecFloat32Canonical(t *testing.T) {
+	s := newTest(t)
+	s.Run(func() {
+		var b [6]byte
+		copy(b[:], s.buf.Draw(6, func(r *rand.Rand, n int) []byte {
+			b := encodefloat32(math.Float32frombits(r.Uint32()))
+			b[r.Intn(len(b))] ^= 1 << uint(r.Intn(8))
+			return b[:]
+		}))
+		f, invalid := decodefloat32(b[:])
+		if invalid {
+			return
+		}
+		if enc := encodefloat32(f); enc != b {
+			s.Fatalf("%x decodes to %v, which encodes as %x", b, f, enc)
+		}
+	})
+}
+
+// TestEncFloat32Order checks that float32 encodings rank
+// the same way as the encodings of the same values as float64,
+// except for subnormals, which are normals as float64, and
+// integers too large for the float32 integer class.
+func TestEncFloat32Order(t *testing.T) {
+	s := newTest(t)
+	s.Run(func() {
+		a := math.Float32frombits(s.Uint32())
+		b := math.Float32frombits(s.Uint32())
+		ea, eb := encodefloat32(a), encodefloat32(b)
+		abits, bbits := math.Float32bits(a), math.Float32bits(b)
+		if (ea == eb) != (abits == bbits) {
+			s.Fatalf("%v and %v have the same encoding %x", a, b, ea)
+		}
+		if abits == bbits || !comparable32(a) || !comparable32(b) {
+			return
+		}
+		if math.IsNaN(float64(a)) && math.IsNaN(float64(b)) {
+			// payloads don't survive the conversion
+			return
+		}
+		c32 := bytes.Compare(ea[:], eb[:])
+		da, db := encodefloat64(float64(a)), encodefloat64(float64(b))
+		if c64 := bytes.Compare(da[:], db[:]); c32 != c64 {
+			s.Fatalf("%v and %v compare %v as float32, %v as float64", a, b, c32, c64)
+		}
+	})
+}
+
+func TestEncFloat32Subnormal(t *testing.T) {
+	sub := encodefloat32(math.SmallestNonzeroFloat32)
+	for _, f := range []float32{1, 0.5, math.MaxFloat32, 0x1p-126} {
+		if enc := encodefloat32(f); bytes.Compare(enc[:], sub[:]) >= 0 {
+			t.Errorf("%v not simpler than subnormal", f)
+		}
+	}
+}
+
+func comparable32(f float32) bool {
+	a := math.Abs(float64(f))
+	if a != 0 && a < 0x1p-126 {
+		return false
+	}
+	return a < 1<<32 || a != math.Trunc(a) || math.IsInf(a, 0)
+}
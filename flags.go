@@ -0,0 +1,49 @@
+package suss
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	seedFlag    = flag.String("suss.seed", "", "seed for the random data generated by suss tests, overrides $SUSS_SEED")
+	profileFlag = flag.String("suss.profile", "", "name of the settings profile used by suss tests, overrides $SUSS_PROFILE")
+	replayFlag  = flag.String("suss.replay", "", "run suss tests once with the given failing example instead of generating data")
+)
+
+// defaultSeed returns the seed given by the -suss.seed flag
+// or the SUSS_SEED environment variable, in that order.
+// If neither is set, the seed is based on the current time.
+func defaultSeed() (int64, error) {
+	s := *seedFlag
+	src := "-suss.seed"
+	if s == "" {
+		s = os.Getenv("SUSS_SEED")
+		src = "SUSS_SEED"
+	}
+	if s == "" {
+		return time.Now().UnixNano(), nil
+	}
+	seed, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s value %q: %v", src, s, err)
+	}
+	return seed, nil
+}
+
+// defaultProfile returns the settings for the profile given by
+// the -suss.profile flag or the SUSS_PROFILE environment variable,
+// in that order. If neither is set, the "default" profile is used.
+func defaultProfile() (Settings, error) {
+	name := *profileFlag
+	if name == "" {
+		name = os.Getenv("SUSS_PROFILE")
+	}
+	if name == "" {
+		name = "default"
+	}
+	return profile(name)
+}
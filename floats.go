@@ -0,0 +1,348 @@
+package suss
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// Float64RangeGen generates float64 values in the range [Lo, Hi].
+// Values outside the range are never generated, unless they are
+// NaN and AllowNaN is set. After Fill, the value can be read from Value.
+//
+// Values are drawn with the same encoding as Float64Gen and wrapped
+// into the range, so they shrink towards 0 if the range contains it
+// and towards the bound closest to 0 otherwise, preferring
+// simple integers along the way.
+type Float64RangeGen struct {
+	Value float64
+	Lo    float64
+	Hi    float64
+
+	// AllowNaN allows NaN values to be generated.
+	AllowNaN bool
+	// AllowInf allows infinities to be generated,
+	// if they are inside the range.
+	AllowInf bool
+	// AllowSubnormal allows subnormal values to be generated.
+	AllowSubnormal bool
+}
+
+// Float64Range returns a generator for float64 values in the
+// range [lo, hi]. The bounds can be infinite, but the infinities
+// are only generated if AllowInf is set. NaN and subnormal
+// values are not generated unless allowed. Fill panics if
+// subnormals aren't allowed, but are the only values in the range.
+func Float64Range(lo, hi float64) *Float64RangeGen {
+	if math.IsNaN(lo) || math.IsNaN(hi) || lo > hi {
+		panic("invalid float range")
+	}
+	return &Float64RangeGen{
+		Lo: lo,
+		Hi: hi,
+	}
+}
+
+func (g *Float64RangeGen) Fill(d Data) {
+	if !g.AllowSubnormal && (g.Lo > 0 || g.Hi < 0) &&
+		math.Abs(g.Lo) < 0x1p-1022 && math.Abs(g.Hi) < 0x1p-1022 {
+		panic("float range only has subnormal values")
+	}
+	fbits := d.Draw(10, func(r *rand.Rand, n int) []byte {
+		b := encodefloat64(g.sample(r))
+		return b[:]
+	})
+	f, invalid := decodefloat64(fbits)
+	if invalid {
+		// rather than wasting the draw, use the
+		// payload as the bits of a float
+		f = math.Float64frombits(binary.BigEndian.Uint64(fbits[2:]))
+	}
+	g.Value = g.constrain(f)
+}
+
+// constrain moves f into the range of allowed values.
+func (g *Float64RangeGen) constrain(f float64) float64 {
+	if g.Lo == math.Inf(1) || g.Hi == math.Inf(-1) {
+		// the range only has an infinity
+		if !g.AllowInf {
+			Invalid()
+		}
+		return g.Lo
+	}
+	switch {
+	case math.IsNaN(f):
+		if g.AllowNaN {
+			return f
+		}
+		f = 0
+	case math.IsInf(f, 0):
+		if g.AllowInf && f >= g.Lo && f <= g.Hi {
+			return f
+		}
+		f = math.Copysign(math.MaxFloat64, f)
+	}
+	f = g.wrap(f)
+	// rounding can take us just outside the range
+	f = math.Min(math.Max(f, g.Lo), g.Hi)
+	if isSubnormal(f) && !g.AllowSubnormal {
+		// move to the closest of 0 and the smallest
+		// normals that is in the range. Fill makes
+		// sure that there is one.
+		switch {
+		case g.Lo <= 0 && g.Hi >= 0:
+			f = math.Copysign(0, f)
+		case g.Lo > 0:
+			f = 0x1p-1022
+		default:
+			f = -0x1p-1022
+		}
+	}
+	if math.IsInf(f, 0) && !g.AllowInf {
+		// wrapping into a range with an infinite bound
+		f = math.Copysign(math.MaxFloat64, f)
+	}
+	return f
+}
+
+// wrap maps a finite float outside the range into it,
+// like drawIndex does for integers, so that values outside
+// the range don't all end up on the bounds. Values close
+// to 0 end up close to the bound closest to 0, which keeps
+// shrinking towards it.
+func (g *Float64RangeGen) wrap(f float64) float64 {
+	lo, hi := g.Lo, g.Hi
+	if f >= lo && f <= hi {
+		return f
+	}
+	switch {
+	case lo > 0:
+		if hi == lo {
+			return lo
+		}
+		return lo + wrapMod(math.Abs(f), hi-lo)
+	case hi < 0:
+		if hi == lo {
+			return hi
+		}
+		return hi - wrapMod(math.Abs(f), hi-lo)
+	case f > 0 && hi > 0:
+		return wrapMod(f, hi)
+	case f < 0 && lo < 0:
+		return -wrapMod(-f, -lo)
+	case lo < 0:
+		// f is positive, but the range has no positive values
+		return -wrapMod(f, -lo)
+	case hi > 0:
+		return wrapMod(-f, hi)
+	}
+	// the range is only 0
+	return 0
+}
+
+// wrapMod returns a value in [0, m] for a positive f.
+// Small values are wrapped with math.Mod. Values so much
+// larger than m that they are integer multiples of it would
+// mostly wrap to 0, so their bits are hashed instead.
+func wrapMod(f, m float64) float64 {
+	if f/m < 1<<52 {
+		return math.Mod(f, m)
+	}
+	h := math.Float64bits(f) * 0x9e3779b97f4a7c15
+	return float64(h>>11) / (1 << 53) * m
+}
+
+func isSubnormal(f float64) bool {
+	return f != 0 && math.Abs(f) < 0x1p-1022
+}
+
+// sample returns values at and near the bounds of the range,
+// and random values inside it.
+func (g *Float64RangeGen) sample(r *rand.Rand) float64 {
+	lo, hi := g.Lo, g.Hi
+	// keep the arithmetic below finite
+	flo := math.Max(lo, -math.MaxFloat64)
+	fhi := math.Min(hi, math.MaxFloat64)
+	switch r.Intn(8) {
+	case 0:
+		nasty := []float64{lo, hi, math.Nextafter(flo, fhi), math.Nextafter(fhi, flo), 0, 1, -1}
+		return nasty[r.Intn(len(nasty))]
+	case 1:
+		if g.AllowNaN {
+			return math.NaN()
+		}
+	case 2:
+		if g.AllowSubnormal {
+			sub := []float64{math.SmallestNonzeroFloat64, 0x1p-1022 - math.SmallestNonzeroFloat64}
+			return math.Copysign(sub[r.Intn(len(sub))], float64(r.Intn(2)*2-1))
+		}
+	case 3:
+		// an integer in the range
+		f := math.Floor(flo/2 + fhi/2 + float64(r.Int63n(1<<20)-1<<19))
+		if f >= lo && f <= hi {
+			return f
+		}
+	case 4:
+		f := nastyFloats[r.Intn(len(nastyFloats))]
+		if f >= lo && f <= hi {
+			return f
+		}
+	}
+	// uniform in the range, halving the bounds so
+	// that the width can't overflow
+	return 2 * (flo/2 + r.Float64()*(fhi/2-flo/2))
+}
+
+// Float64Range is a convenience function that returns
+// a float64 in the range [lo, hi] from the Runner.
+// It never returns NaN, infinities or subnormal values.
+func (r *Runner) Float64Range(lo, hi float64) float64 {
+	g := Float64Range(lo, hi)
+	r.Draw(g)
+	return g.Value
+}
+
+// Float32Gen implements a generator for float32 values.
+// It uses an encoding like the one used by Float64Gen,
+// but made from the bits of a float32, so that every float32
+// is drawn directly and shrinks the same way a float64 does.
+type Float32Gen float32
+
+var nastyFloat32s = []float32{
+	0.0, 0.5, 1.0 / 3, 1, 10e6, 10e-6, math.MaxFloat32,
+	math.SmallestNonzeroFloat32, 1.17549435e-38, 16777216, 16777217,
+	1.192092896e-07, 1 - 1.192092896e-07,
+}
+
+func init() {
+	n := []float32{float32(math.NaN()), float32(math.Inf(0))}
+	for i := 0; i < 3; i++ {
+		nastyFloat32s = append(nastyFloat32s, n...)
+	}
+	for _, f := range nastyFloat32s {
+		nastyFloat32s = append(nastyFloat32s, -f)
+	}
+}
+
+func (f *Float32Gen) Fill(d Data) {
+	fbits := d.Draw(6, func(r *rand.Rand, n int) []byte {
+		var f float32
+		switch r.Intn(4) {
+		case 0, 1:
+			f = nastyFloat32s[r.Intn(len(nastyFloat32s))]
+		case 2:
+			f = r.Float32() * float32(r.Intn(2)*2-1)
+		default:
+			f = math.Float32frombits(r.Uint32())
+		}
+		b := encodefloat32(f)
+		return b[:]
+	})
+	fl, invalid := decodefloat32(fbits)
+	if invalid {
+		Invalid()
+	}
+	*f = Float32Gen(fl)
+}
+
+// encodefloat32 is encodefloat64 for float32 values.
+// The encoding is a sign byte, a class byte and 4 bytes of
+// big endian payload. Normal numbers have 9 bits of exponent
+// code and 23 bits of reversed mantissa.
+func encodefloat32(f float32) [6]byte {
+	var b [6]byte
+	fbits := math.Float32bits(f)
+	b[0] = byte(fbits >> 31)
+	mant := fbits & mantMask32
+	sexp := int((fbits >> 23) & 0xff)
+	abs := math.Abs(float64(f))
+
+	var payload uint32
+	switch {
+	case sexp == 0xff:
+		b[1] = floatSpecial
+		payload = mant
+	case abs < 1<<32 && abs == math.Trunc(abs):
+		b[1] = floatInteger
+		payload = uint32(abs)
+	case sexp == 0:
+		b[1] = floatSubnormal
+		payload = mant
+	default:
+		b[1] = floatNormal
+		payload = uint32(expcode32(sexp-127))<<23 | reverseMant32(mant)
+	}
+	binary.BigEndian.PutUint32(b[2:], payload)
+	return b
+}
+
+const mantMask32 = 1<<23 - 1
+
+func expcode32(exp int) uint16 {
+	if exp >= 0 {
+		return uint16(exp)
+	}
+	return uint16(127 - exp)
+}
+
+func reverseMant32(m uint32) uint32 {
+	return bits.Reverse32(m) >> 9
+}
+
+// decodefloat32 decodes a float encoded with encodefloat32.
+// Byte sequences that are not the encoding of any float
+// are reported as invalid.
+func decodefloat32(b []byte) (float32, bool) {
+	sign := b[0]
+	if sign != 0 && sign != 1 {
+		return 0, true
+	}
+	payload := binary.BigEndian.Uint32(b[2:])
+	var f float32
+	switch b[1] {
+	case floatInteger:
+		if payload != 0 && bits.Len32(payload)-bits.TrailingZeros32(payload) > 24 {
+			return 0, true
+		}
+		f = float32(payload)
+	case floatNormal:
+		code := payload >> 23
+		if code > 253 {
+			return 0, true
+		}
+		exp := int(code)
+		if exp > 127 {
+			exp = 127 - exp
+		}
+		mant := reverseMant32(payload & mantMask32)
+		f = math.Float32frombits(uint32(exp+127)<<23 | mant)
+		if f < 1<<32 && f == float32(math.Trunc(float64(f))) {
+			return 0, true
+		}
+	case floatSubnormal:
+		if payload == 0 || payload > mantMask32 {
+			return 0, true
+		}
+		f = math.Float32frombits(payload)
+	case floatSpecial:
+		if payload > mantMask32 {
+			return 0, true
+		}
+		f = math.Float32frombits(0xff<<23 | payload)
+	default:
+		return 0, true
+	}
+	if sign == 1 {
+		f = math.Float32frombits(math.Float32bits(f) | 1<<31)
+	}
+	return f, false
+}
+
+// Float32 is a convenience function that returns
+// a float32 value from the Runner.
+func (r *Runner) Float32() float32 {
+	var f Float32Gen
+	r.Draw(&f)
+	return float32(f)
+}
@@ -0,0 +1,113 @@
+package suss
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFloat64Range(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		lo, hi := s.Float64(), s.Float64()
+		if math.IsNaN(lo) || math.IsNaN(hi) {
+			Invalid()
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if math.IsInf(lo, 1) || math.IsInf(hi, -1) {
+			// no finite values in range
+			Invalid()
+		}
+		g := Float64Range(lo, hi)
+		g.AllowSubnormal = s.Bool()
+		if !g.AllowSubnormal && (lo > 0 || hi < 0) && isSubnormal(lo) && isSubnormal(hi) {
+			// only subnormals in range
+			Invalid()
+		}
+		s.Draw(g)
+		f := g.Value
+		if math.IsNaN(f) || math.IsInf(f, 0) || f < lo || f > hi {
+			s.Fatalf("value %v outside [%v, %v]", f, lo, hi)
+		}
+		if !g.AllowSubnormal && isSubnormal(f) {
+			s.Fatalf("subnormal value %v", f)
+		}
+	})
+}
+
+func TestFloat64RangeSimplest(t *testing.T) {
+	for _, tt := range []struct{ lo, hi, want float64 }{
+		{-1, 1, 0},
+		{5, 10, 5},
+		{-10, -5, -5},
+		{math.Inf(-1), math.Inf(1), 0},
+	} {
+		g := Float64Range(tt.lo, tt.hi)
+		g.Fill(bufFromBytes(make([]byte, 10)))
+		if g.Value != tt.want {
+			t.Errorf("simplest value in [%v, %v] = %v, want %v", tt.lo, tt.hi, g.Value, tt.want)
+		}
+	}
+}
+
+// TestFloat64RangeSpread checks that values drawn outside
+// the range are spread over it instead of piling up on the bounds.
+func TestFloat64RangeSpread(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	n, bounds := 0, 0
+	s.Run(func() {
+		g := Float64Range(5, 10)
+		s.Draw(g)
+		n++
+		if g.Value == 5 || g.Value == 10 {
+			bounds++
+		}
+	})
+	// 0 wraps to Lo, the simplest value in the range,
+	// so some values end up there
+	if bounds*3 > n {
+		t.Errorf("%d of %d values on the bounds", bounds, n)
+	}
+}
+
+func TestFloat32(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	inf, sub := 0, 0
+	s.Run(func() {
+		f := s.Float32()
+		if math.IsInf(float64(f), 0) {
+			inf++
+		}
+		if f != 0 && math.Abs(float64(f)) < 0x1p-126 {
+			sub++
+		}
+	})
+	if inf*4 > s.Stats().Valid {
+		t.Errorf("%d of %d values infinite", inf, s.Stats().Valid)
+	}
+	if sub == 0 {
+		t.Errorf("no subnormal values")
+	}
+}
+
+func TestFloat64RangeNoInvalid(t *testing.T) {
+	for _, r := range [][2]float64{{3, 3}, {-1, 1}, {0, math.Inf(1)}} {
+		s := newTest(t).WithSettings(Settings{MaxExamples: 300})
+		s.Run(func() {
+			s.Draw(Float64Range(r[0], r[1]))
+		})
+		if st := s.Stats(); st.Invalid != 0 {
+			t.Errorf("[%v, %v]: %d invalid draws", r[0], r[1], st.Invalid)
+		}
+	}
+}
+
+func TestFloat64RangeOnlySubnormal(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("range with only subnormal values did not panic")
+		}
+	}()
+	Float64Range(1e-310, 2e-310).Fill(bufFromBytes(make([]byte, 10)))
+}
@@ -0,0 +1,102 @@
+package suss
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// Fuzz runs a suspicion test with data from Go's native fuzzing.
+// It is called from a fuzz target, and the test function gets
+// the Runner to draw from.
+//
+//	func FuzzSort(f *testing.F) {
+//		suss.Fuzz(f, func(r *suss.Runner) {
+//			...
+//		})
+//	}
+//
+// With go test -fuzz, the fuzzing engine supplies the bytes that
+// the generators draw, so the property is explored with coverage
+// guidance instead of random generation. Without -fuzz, only the
+// seed corpus is run. Inputs shorter than the data the test
+// draws are padded with zeros. The -suss.replay test flag runs the given
+// example instead of the seed corpus.
+//
+// When an input fails, it is shrunk like a failure found by Run
+// and the minimal example is reported and saved. The examples
+// saved for the fuzz target are added to the seed corpus, so that
+// they keep failing the target until the bug is fixed.
+func Fuzz(f *testing.F, fn func(r *Runner)) {
+	f.Helper()
+	FuzzWithSettings(f, Settings{}, fn)
+}
+
+// FuzzWithSettings is like Fuzz, but runs the test function
+// with the given settings, like Runner.WithSettings.
+//
+// The fuzzing engine discards the standard output of the processes
+// running the inputs, so output is always captured with CaptureLog,
+// and Runner.Logf is the way to report what the test found.
+func FuzzWithSettings(f *testing.F, s Settings, fn func(r *Runner)) {
+	f.Helper()
+	db := newExampleDB(f.Name())
+	newFuzzRunner := func(t *testing.T) *Runner {
+		r := newRunner(t, db).WithSettings(s)
+		r.settings.Capture = CaptureLog
+		r.testfunc = func() { fn(r) }
+		return r
+	}
+	if *replayFlag != "" {
+		// run the example instead of the corpus
+		byt, err := decodeExample(*replayFlag)
+		if err != nil {
+			f.Fatalf("suss: invalid example %q: %v", *replayFlag, err)
+		}
+		f.Add(byt)
+		f.Fuzz(func(t *testing.T, in []byte) {
+			if !bytes.Equal(in, byt) {
+				t.Skip("suss: replaying an example")
+			}
+			r := newFuzzRunner(t)
+			r.replay = byt
+			r.runReplay()
+		})
+		return
+	}
+	examples, err := db.fetch()
+	if err != nil {
+		f.Logf("suss: could not read examples: %v", err)
+	}
+	saved := make(map[string]bool)
+	for _, byt := range examples {
+		saved[db.key(byt)] = true
+		f.Add(byt)
+	}
+	// the all zero buffer draws the simplest values
+	// for most generators, which makes it a good start
+	f.Add(make([]byte, 64))
+	f.Fuzz(func(t *testing.T, byt []byte) {
+		r := newFuzzRunner(t)
+		r.startTime = time.Now()
+		// most inputs from the fuzzing engine are short,
+		// pad them instead of throwing them away
+		r.runExample(byt)
+		if r.buf.status != statusInteresting {
+			r.buf.discard()
+			if saved[db.key(byt)] {
+				// a stored example that no longer fails
+				if err := db.delete(byt); err != nil {
+					t.Logf("suss: could not delete example: %v", err)
+				}
+			}
+			return
+		}
+		r.lastBuf = r.buf
+		if saved[db.key(byt)] {
+			r.dbExample = byt
+		}
+		r.reportFailure()
+		t.FailNow()
+	})
+}
@@ -0,0 +1,96 @@
+package suss
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func FuzzIntRange(f *testing.F) {
+	Fuzz(f, func(r *Runner) {
+		lo, hi := r.Int64(), r.Int64()
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if v := r.Int64Range(lo, hi); v < lo || v > hi {
+			r.Fatalf("%d outside [%d, %d]", v, lo, hi)
+		}
+	})
+}
+
+// FuzzFailing is run by TestFuzzFailing in a subprocess.
+func FuzzFailing(f *testing.F) {
+	if !inSubprocess() {
+		f.Skip("run by TestFuzzFailing")
+	}
+	// the example database is relative to the working directory
+	if err := os.Chdir(os.Getenv("SUSS_TEST_DIR")); err != nil {
+		f.Fatal(err)
+	}
+	fixed := os.Getenv("SUSS_TEST_FIXED") != ""
+	// too short for IntRange, but fails once padded with zeros
+	f.Add([]byte{0xff})
+	// the fuzzing engine discards stdout, so
+	// output is captured in the log regardless
+	FuzzWithSettings(f, Settings{Capture: CaptureStdout}, func(r *Runner) {
+		v := r.IntRange(0, 1000)
+		if v >= 10 && !fixed {
+			r.Fatalf("too big: %d", v)
+		}
+	})
+}
+
+func TestFuzzFailing(t *testing.T) {
+	dir := t.TempDir()
+	run := []string{"-test.run=^FuzzFailing$"}
+	out, failed := runTestSubprocess(t, run, "SUSS_TEST_DIR="+dir)
+	if !failed {
+		t.Fatalf("fuzz target did not fail:\n%s", out)
+	}
+	// the failing seed is shrunk before it is reported
+	// and the output is reported with t.Log
+	if !regexp.MustCompile(`\.go:\d+: too big: 10\n`).MatchString(out) {
+		t.Fatalf("shrunk failure not logged:\n%s", out)
+	}
+	if n := strings.Count(out, "too big"); n != 1 {
+		t.Fatalf("%d failures reported, want 1:\n%s", n, out)
+	}
+	db := &exampleDB{dir: filepath.Join(dir, "testdata", "suss", "FuzzFailing")}
+	examples, err := db.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 1 {
+		t.Fatalf("%d examples saved, want 1", len(examples))
+	}
+	s := newTest(t)
+	s.buf = bufFromBytes(examples[0])
+	if v := s.IntRange(0, 1000); v != 10 {
+		t.Fatalf("saved example draws %d, want 10", v)
+	}
+
+	// replaying the example runs it once, without shrinking
+	replay := append(run, "-suss.replay="+encodeExample(examples[0]))
+	out, failed = runTestSubprocess(t, replay, "SUSS_TEST_DIR="+dir)
+	if !failed || !strings.Contains(out, "too big: 10\n") {
+		t.Fatalf("replayed example did not fail:\n%s", out)
+	}
+	if n := strings.Count(out, "too big"); n != 1 || strings.Contains(out, "-suss.replay=") {
+		t.Fatalf("example not replayed on its own:\n%s", out)
+	}
+
+	// once the bug is fixed, the saved example passes and is evicted
+	out, failed = runTestSubprocess(t, run, "SUSS_TEST_DIR="+dir, "SUSS_TEST_FIXED=1")
+	if failed {
+		t.Fatalf("fixed fuzz target failed:\n%s", out)
+	}
+	examples, err = db.fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(examples) != 0 {
+		t.Fatalf("passing examples not evicted: %x", examples)
+	}
+}
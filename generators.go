@@ -3,6 +3,7 @@ package suss
 import (
 	"encoding/binary"
 	"math"
+	"math/bits"
 	"math/rand"
 )
 
@@ -12,6 +13,10 @@ type SliceGen struct {
 	Max int
 
 	f func()
+
+	draw func() interface{}
+	key  func(v interface{}) interface{}
+	add  func(v interface{})
 }
 
 // Generator generates
@@ -83,7 +88,64 @@ func Slice(f func()) *SliceGen {
 	}
 }
 
+// UniqueSlice returns a generator for a slice of distinct values.
+// For every element, draw is called to draw a candidate. If its key
+// is new, add is called with it and it's the functions responsibility
+// to add it to the slice. Duplicates are dropped and redrawn.
+// Min and Max bound the number of distinct elements.
+//
+// key returns the key that decides whether two elements are
+// duplicates. If key is nil, the elements themselves are used
+// as keys and must be comparable.
+//
+//	var f []int
+//	s := suss.UniqueSlice(func() interface{} {
+//		return runner.Int()
+//	}, nil, func(v interface{}) {
+//		f = append(f, v.(int))
+//	})
+//	runner.Draw(s)
+func UniqueSlice(draw func() interface{}, key func(v interface{}) interface{}, add func(v interface{})) *SliceGen {
+	s := Slice(nil)
+	s.draw = draw
+	s.key = key
+	s.add = add
+	return s
+}
+
 func (s *SliceGen) Fill(d Data) {
+	if s.draw == nil {
+		fillSlice(d, s.Avg, s.Min, s.Max, func() bool {
+			s.f()
+			return true
+		})
+		return
+	}
+	seen := make(map[interface{}]bool)
+	fillSlice(d, s.Avg, s.Min, s.Max, func() bool {
+		v := s.draw()
+		k := v
+		if s.key != nil {
+			k = s.key(v)
+		}
+		if seen[k] {
+			return false
+		}
+		seen[k] = true
+		s.add(v)
+		return true
+	})
+}
+
+// uniqueRetries is the number of duplicates in a row that
+// a unique collection draws before it stops growing.
+const uniqueRetries = 10
+
+// fillSlice is the loop shared by all the collection
+// generators. add draws an element and reports whether
+// it was added. Elements that are not added are
+// duplicates in a unique collection and are redrawn.
+func fillSlice(d Data, avg, min, max int, add func() bool) {
 	// The intuitive way to turn an infinite bytestream into a
 	// slice would be to grab a value at the beginning
 	// and then generate that number of elements
@@ -95,29 +157,41 @@ func (s *SliceGen) Fill(d Data) {
 	// stream turns into the element not being
 	// added.
 	l := uint64(0)
-	stopvalue := 1 - (1.0 / (1 + float64(s.Avg)))
-	if s.Min < 0 {
+	stopvalue := 1 - (1.0 / (1 + float64(avg)))
+	if min < 0 {
 		panic("invalid min slice length")
 	}
-	min := uint64(s.Min)
-	max := uint64(s.Max)
-	for l < max {
+	umin := uint64(min)
+	umax := uint64(max)
+	dups := 0
+	for l < umax {
 		d.StartExample()
 		more := biasBool(d, stopvalue)
-		if !more && l >= min {
+		if !more && l >= umin {
 			d.EndExample()
 			return
 		}
-		l++
-		sliceCall(s.f, d)
+		if sliceCall(add, d) {
+			l++
+			dups = 0
+			continue
+		}
+		dups++
+		if dups > uniqueRetries {
+			// we can't seem to find any new elements
+			if l >= umin {
+				return
+			}
+			Invalid()
+		}
 	}
 }
 
 // Since slice functions can panic, make sure that we
 // always call d.EndExample
-func sliceCall(f func(), d Data) {
+func sliceCall(f func() bool, d Data) bool {
 	defer d.EndExample()
-	f()
+	return f()
 }
 
 // BoolGen implements a generator for boolean values.
@@ -176,9 +250,7 @@ func (f *Float64Gen) Fill(d Data) {
 		case flavor <= 4:
 			f = nastyFloats[r.Intn(len(nastyFloats))]
 		case flavor == 5:
-			var b [10]byte
-			r.Read(b[:])
-			return b[:]
+			f = math.Float64frombits(r.Uint64())
 		case flavor == 6:
 			f = r.Float64() * float64((r.Intn(2)*2)-1)
 		case flavor == 7:
@@ -208,149 +280,135 @@ func (f *Float64Gen) Fill(d Data) {
 	*f = Float64Gen(fl)
 }
 
-// encodefloat64 attempts to encode a floating point number
-// so that its lexicographical ordering follows human intuition
+// encodefloat64 encodes a floating point number so that
+// its lexicographical ordering follows human intuition
 //
 // Design goals were:
-// - Integers are simpler than fractionals
-// - positive numbers are simpler than negative ones
-// - exponents of smaller magnitude are simpler, regardless of sign
-// - 0 is the simplest number, 1 is the second most simple number
+//   - 0 is the simplest number, 1 is the second most simple number
+//   - positive numbers are simpler than negative ones
+//   - integers are simpler than fractionals, smaller integers
+//     are simpler than larger ones
+//   - fractionals with fewer significant digits are simpler
+//   - exponents of smaller magnitude are simpler, positive
+//     exponents before negative ones
+//   - subnormals are more complex than any normal number
+//     and infinities and NaNs are more complex still
+//   - every float64 has exactly one encoding, so shrinking
+//     never wastes time on byte sequences that are the same float
+//
+// The encoding is a sign byte, a class byte and 8 bytes of
+// big endian payload, whose meaning depends on the class:
+//
+//	floatInteger:   the integer
+//	floatNormal:    11 bits of exponent code and 52 bits of reversed mantissa
+//	floatSubnormal: the mantissa
+//	floatSpecial:   the mantissa, 0 for infinity and NaN payload otherwise
 func encodefloat64(f float64) [10]byte {
 	var b [10]byte
-	bits := math.Float64bits(f)
+	fbits := math.Float64bits(f)
 	// encode the sign bit as a single byte
-	b[0] = byte((bits & (1 << 63)) >> 63)
-
-	// for the mantissa, we want simpler fractions
-	// This means we get numbers that require fewer
-	// digits to print it
-	// Encoding as a little endian number
-	// makes shrinking go towards a number with
-	// fewer significant digits
-	mant := bits & (^uint64(0) >> (64 - 52))
-	binary.LittleEndian.PutUint64(b[1:], mant)
-
-	// if the exponent is 0, that means this value
-	// is a zero. don't unbias the exponent in this case
-	// TODO: handle subnormals so that they're more complex
-	sexp := int16((bits >> 52) & 0x7ff)
-	var exp uint16
-	if sexp == 0 {
-		if mant != 0 {
-			// subnormal number, use the extra range we get from
-			// int16 to signal this
-			exp = sint16tolex16(-1024)
-		}
-	} else if sexp == 0x7ff {
-		// infinity and NaN, they're more complex that negative
-		// exponent and subnormals
-		exp = sint16tolex16(-1025)
-	} else {
-		// regular exponent
-		// unbias
-		sexp -= 1023
-		// if exponent is positive, bias it +1
-		// so that an exponent of 1 becomes 0
-		// This keeps the invariant that 0 is
-		// simpler than 1
-		if sexp >= 0 {
-			exp = uint16(sexp) + 1
-		} else {
-			// for negative exponents
-			// use signed regular integer
-			// This makes -1 simpler than -2
-			// when interpreted as a byte stream
-			// the sign keeps the invariant that
-			// integers are simpler than fractionals
-			exp = sint16tolex16(sexp)
-		}
+	b[0] = byte(fbits >> 63)
+	mant := fbits & mantMask
+	sexp := int((fbits >> 52) & 0x7ff)
+	abs := math.Abs(f)
+
+	var payload uint64
+	switch {
+	case sexp == 0x7ff:
+		b[1] = floatSpecial
+		payload = mant
+	case abs < 1<<64 && abs == math.Trunc(abs):
+		// includes zero
+		b[1] = floatInteger
+		payload = uint64(abs)
+	case sexp == 0:
+		b[1] = floatSubnormal
+		payload = mant
+	default:
+		b[1] = floatNormal
+		payload = uint64(expcode(sexp-1023))<<52 | reverseMant(mant)
 	}
-	binary.BigEndian.PutUint16(b[8:], exp)
+	binary.BigEndian.PutUint64(b[2:], payload)
 	return b
 }
 
-func sint16tolex16(s int16) uint16 {
-	s *= -1
-	exp := uint16(s)
-	exp ^= (1 << 15)
-	return exp
+// float classes, in order of increasing complexity
+const (
+	floatInteger = iota
+	floatNormal
+	floatSubnormal
+	floatSpecial
+)
+
+const mantMask = 1<<52 - 1
+
+// expcode orders the exponents of normal numbers.
+// Positive exponents come first, counting up, followed
+// by negative ones, counting down.
+func expcode(exp int) uint16 {
+	if exp >= 0 {
+		return uint16(exp)
+	}
+	return uint16(1023 - exp)
+}
+
+// reverseMant reverses the bits of the mantissa.
+// For fractionals, the last bits of the mantissa are the
+// least significant digits. Reversing them makes shrinking
+// clear them first, giving numbers with fewer digits.
+func reverseMant(m uint64) uint64 {
+	return bits.Reverse64(m) >> 12
 }
 
+// decodefloat64 decodes a float encoded with encodefloat64.
+// Byte sequences that are not the encoding of any float
+// are reported as invalid.
 func decodefloat64(b []byte) (float64, bool) {
-	fbits := uint64(0)
 	sign := b[0]
 	if sign != 0 && sign != 1 {
 		return 0, true
 	}
-	fbits = uint64(sign) << 63
-	// mantissa only take 7 bytes in our binary packing
-	// but binary only lets us read in chunks of 8
-	// copy the mantissa value into an empty array
-	// and then decode to make sure that we don't
-	var mb [8]byte
-	copy(mb[:], b[1:8])
-	mant := binary.LittleEndian.Uint64(mb[:])
-
-	exp := binary.BigEndian.Uint16(b[8:])
-	if exp&(1<<15) != 0 {
-		// this is a signed exponent
-		// clear the sign bit
-		sexp := int16(exp & (^uint16(0) >> 1))
-		if sexp == 1024 {
-			exp = 0
-		} else if sexp == 1025 {
-			exp = 0x7ff
-		} else {
-			// this is a regular negative exponent
-			// make into negative number
-			sexp *= -1
-			// bias
-			sexp += 1023
-			exp = uint16(sexp)
+	payload := binary.BigEndian.Uint64(b[2:])
+	var f float64
+	switch b[1] {
+	case floatInteger:
+		// the integer has to fit in the mantissa exactly
+		if payload != 0 && bits.Len64(payload)-bits.TrailingZeros64(payload) > 53 {
+			return 0, true
+		}
+		f = float64(payload)
+	case floatNormal:
+		code := payload >> 52
+		if code > 2045 {
+			return 0, true
 		}
-	} else if exp != 0 {
-		// positive exponent
-		exp -= 1
-		exp += 1023
-	} else if mant != 0 {
-		mant = 0
+		exp := int(code)
+		if exp > 1023 {
+			exp = 1023 - exp
+		}
+		mant := reverseMant(payload & mantMask)
+		f = math.Float64frombits(uint64(exp+1023)<<52 | mant)
+		if f < 1<<64 && f == math.Trunc(f) {
+			// integers have their own class
+			return 0, true
+		}
+	case floatSubnormal:
+		if payload == 0 || payload > mantMask {
+			return 0, true
+		}
+		f = math.Float64frombits(payload)
+	case floatSpecial:
+		if payload > mantMask {
+			return 0, true
+		}
+		f = math.Float64frombits(0x7ff<<52 | payload)
+	default:
+		return 0, true
 	}
-	fbits ^= mant & (^uint64(0) >> (64 - 52))
-	fbits ^= uint64(exp) << 52
-	return math.Float64frombits(fbits), false
-}
-
-// Uint64Gen implements a generator for uint64 values.
-type Uint64Gen uint64
-
-func (u *Uint64Gen) Fill(d Data) {
-	b := d.Draw(8, Uniform)
-	*u = Uint64Gen(binary.BigEndian.Uint64(b))
-}
-
-// Uint64 is a convenience function that returns
-// a uint64 value from the Runner.
-func (r *Runner) Uint64() uint64 {
-	var u Uint64Gen
-	r.Draw(&u)
-	return uint64(u)
-}
-
-// Int16Gen implements a generator for int16 values.
-type Int16Gen int16
-
-func (i *Int16Gen) Fill(d Data) {
-	f := d.Draw(2, Uniform)
-	*i = Int16Gen(binary.BigEndian.Uint16(f))
-}
-
-// Int16 is a convenience function that returns
-// a int16 value from the Runner.
-func (r *Runner) Int16() int16 {
-	var i Int16Gen
-	r.Draw(&i)
-	return int16(i)
+	if sign == 1 {
+		f = math.Float64frombits(math.Float64bits(f) | 1<<63)
+	}
+	return f, false
 }
 
 // ByteGen implements a generator for byte values.
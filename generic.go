@@ -0,0 +1,122 @@
+package suss
+
+// Gen is a generator for values of type T.
+//
+// Unlike Generator, which stores the value it generates
+// in its receiver, Gen returns the value. This lets
+// generators be combined without naming a type for every
+// value or appending to captured variables.
+//
+// Generate draws bytes from the Data interface, in the same
+// way Fill does for Generator. Generators that draw from other
+// generators should use DrawFrom, so that every value drawn
+// is its own example for the shrinker.
+type Gen[T any] interface {
+	Generate(d Data) T
+}
+
+// GenFunc is an adapter to allow the use of ordinary
+// functions as generators.
+type GenFunc[T any] func(d Data) T
+
+func (f GenFunc[T]) Generate(d Data) T {
+	return f(d)
+}
+
+// Draw draws a value from g using data from the Runner.
+// It is the Gen equivalent of Runner.Draw.
+func Draw[T any](r *Runner, g Gen[T]) T {
+	return DrawFrom(r.buf, g)
+}
+
+// DrawFrom draws a value from g with data from d,
+// wrapping the draw in StartExample and EndExample.
+func DrawFrom[T any](d Data, g Gen[T]) T {
+	d.StartExample()
+	v := g.Generate(d)
+	d.EndExample()
+	return v
+}
+
+// FromGenerator returns a Gen for a type implementing Generator
+// through its pointer. Every value generated is a newly filled
+// zero value of that type.
+//
+//	g := suss.FromGenerator[suss.Float64Gen]()
+//	f := float64(suss.Draw(runner, g))
+func FromGenerator[T any, P interface {
+	*T
+	Generator
+}]() Gen[T] {
+	return GenFunc[T](func(d Data) T {
+		var v T
+		P(&v).Fill(d)
+		return v
+	})
+}
+
+// ToGenerator adapts g to the Generator interface.
+// Every call to Fill stores the generated value in dst.
+func ToGenerator[T any](g Gen[T], dst *T) Generator {
+	return &genAdapter[T]{g: g, dst: dst}
+}
+
+type genAdapter[T any] struct {
+	g   Gen[T]
+	dst *T
+}
+
+func (a *genAdapter[T]) Fill(d Data) {
+	*a.dst = a.g.Generate(d)
+}
+
+// SliceOfGen generates slices with elements drawn from Elem.
+// Avg, Min and Max work like they do for SliceGen.
+//
+// If Key is non-nil, the slice only contains elements with
+// distinct keys. Duplicates are dropped and redrawn and Min
+// and Max bound the number of distinct elements.
+type SliceOfGen[T any] struct {
+	Elem Gen[T]
+	Avg  int
+	Min  int
+	Max  int
+	Key  func(T) interface{}
+}
+
+// SliceOf returns a generator for slices with elements drawn from elem.
+//
+//	floats := suss.SliceOf(suss.FromGenerator[suss.Float64Gen]())
+//	f := suss.Draw(runner, floats)
+func SliceOf[T any](elem Gen[T]) *SliceOfGen[T] {
+	sl := Slice(nil)
+	return &SliceOfGen[T]{
+		Elem: elem,
+		Avg:  sl.Avg,
+		Min:  sl.Min,
+		Max:  sl.Max,
+	}
+}
+
+func (s *SliceOfGen[T]) Generate(d Data) []T {
+	var res []T
+	var seen map[interface{}]bool
+	if s.Key != nil {
+		seen = make(map[interface{}]bool)
+	}
+	// fillSlice wraps every element in an example,
+	// so there's no need for DrawFrom here
+	fillSlice(d, s.Avg, s.Min, s.Max, func() bool {
+		v := s.Elem.Generate(d)
+		if seen != nil {
+			k := s.Key(v)
+			if seen[k] {
+				return false
+			}
+			seen[k] = true
+		}
+		res = append(res, v)
+		return true
+	})
+	return res
+}
@@ -0,0 +1,72 @@
+package suss
+
+import (
+	"testing"
+)
+
+func TestGenAdapters(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		bytes := SliceOf(FromGenerator[Uint8Gen]())
+		bytes.Max = 4
+		b := Draw(s, bytes)
+		if len(b) > 4 {
+			s.Fatalf("slice too long: %v", b)
+		}
+		var small int16
+		s.Draw(ToGenerator[int16](GenFunc[int16](func(d Data) int16 {
+			return int16(drawInt(d, -3, 3))
+		}), &small))
+		if small < -3 || small > 3 {
+			s.Fatalf("value out of range: %v", small)
+		}
+	})
+}
+
+func TestCombinators(t *testing.T) {
+	small := GenFunc[int](func(d Data) int {
+		return int(drawInt(d, 0, 10))
+	})
+	even := Filter(small, func(i int) bool { return i%2 == 0 })
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		if v := Draw(s, Map(even, func(i int) int { return i + 1 })); v%2 != 1 {
+			s.Fatalf("mapped even number is even: %v", v)
+		}
+		pair := FlatMap(small, func(n int) Gen[[2]int] {
+			return Map(SampledFrom(0, n), func(m int) [2]int { return [2]int{n, m} })
+		})
+		if p := Draw(s, pair); p[1] != 0 && p[1] != p[0] {
+			s.Fatalf("dependent value not drawn from its generator: %v", p)
+		}
+		if v := Draw(s, OneOf(Just(-1), small)); v < -1 || v > 10 {
+			s.Fatalf("OneOf value from no generator: %v", v)
+		}
+	})
+}
+
+func TestOneOfShrinksToFirst(t *testing.T) {
+	g := OneOf(Just("first"), Just("second"))
+	if v := DrawFrom(bufFromBytes([]byte{0}), g); v != "first" {
+		t.Fatalf("simplest OneOf value = %q, want first", v)
+	}
+}
+
+func TestFrequency(t *testing.T) {
+	g := Frequency([]Weighted[int]{
+		{0, Just(0)},
+		{80, Just(1)},
+		{20, Just(2)},
+	})
+	if v := DrawFrom(bufFromBytes([]byte{0}), g); v != 0 {
+		t.Fatalf("simplest Frequency value = %v, want 0", v)
+	}
+	counts := make([]int, 3)
+	s := newTest(t).WithSettings(Settings{MaxExamples: 1000})
+	s.Run(func() {
+		counts[Draw(s, g)]++
+	})
+	if counts[1] < counts[2] {
+		t.Errorf("weights not respected, got counts %v", counts)
+	}
+}
@@ -0,0 +1,110 @@
+package suss
+
+import (
+	"fmt"
+	"time"
+)
+
+// HealthCheck is a set of health checks. Health checks fail
+// a test when the test can't find failures in a meaningful way,
+// e.g. because almost every example is invalid.
+type HealthCheck int
+
+const (
+	// HealthInvalid fails the test when many examples are marked
+	// invalid before a few valid ones are found.
+	HealthInvalid HealthCheck = 1 << iota
+
+	// HealthOverrun fails the test when many examples draw more
+	// data than Settings.MaxSize allows before a few valid
+	// ones are found.
+	HealthOverrun
+
+	// HealthSlow fails the test when drawing data for the first
+	// few examples takes more than half of Settings.GenerateTime.
+	// Only the time spent drawing bytes counts, not the time
+	// spent in the test function or turning bytes into values.
+	HealthSlow
+
+	// HealthNondeterministic fails the test when an example that
+	// failed passes when it is run again with the same data.
+	// The test function then depends on something besides the
+	// data drawn from the Runner, and shrinking it is pointless.
+	HealthNondeterministic
+
+	// HealthAll is every health check.
+	HealthAll = HealthInvalid | HealthOverrun | HealthSlow | HealthNondeterministic
+)
+
+// The health checks only look at the examples run before
+// healthValid valid examples have been found.
+const (
+	healthValid   = 10
+	healthInvalid = 50
+	healthOverrun = 20
+)
+
+func (h HealthCheck) String() string {
+	switch h {
+	case HealthInvalid:
+		return "HealthInvalid"
+	case HealthOverrun:
+		return "HealthOverrun"
+	case HealthSlow:
+		return "HealthSlow"
+	case HealthNondeterministic:
+		return "HealthNondeterministic"
+	}
+	return fmt.Sprintf("HealthCheck(%d)", int(h))
+}
+
+// checkHealth fails the test if the examples
+// generated so far are unhealthy.
+func (r *Runner) checkHealth() {
+	s := &r.stats
+	if s.Valid >= healthValid {
+		return
+	}
+	switch {
+	case s.Invalid >= healthInvalid:
+		r.healthFail(HealthInvalid, "%d examples were invalid and only %d valid. "+
+			"Generating valid data directly is much more efficient than calling Invalid or using Filter",
+			s.Invalid, s.Valid)
+	case s.Overrun >= healthOverrun:
+		r.healthFail(HealthOverrun, "%d examples drew more than %d bytes and only %d were valid. "+
+			"Make the generated data smaller or raise Settings.MaxSize",
+			s.Overrun, r.settings.MaxSize, s.Valid)
+	case s.drawTime > r.settings.GenerateTime/2:
+		r.healthFail(HealthSlow, "drawing data for the first %d examples took %v, "+
+			"so few examples can be tried in %v. Generate smaller or simpler data",
+			s.Examples, s.drawTime.Round(time.Millisecond), r.settings.GenerateTime)
+	}
+}
+
+// checkDeterministic runs the test function again with the
+// data of the failing example in lastBuf, to make sure
+// that it fails again.
+func (r *Runner) checkDeterministic() {
+	byt := r.lastBuf.buf
+	r.buf = bufFromBytes(byt)
+	r.runOnce()
+	r.buf.discard()
+	if r.buf.status == statusInteresting {
+		return
+	}
+	r.healthFail(HealthNondeterministic, "the example %s failed, but it did not fail when run again with the same data. "+
+		"The test must only depend on data drawn from the Runner",
+		encodeExample(byt))
+}
+
+func (r *Runner) healthFail(h HealthCheck, format string, args ...interface{}) {
+	if r.settings.SuppressHealthChecks&h != 0 {
+		return
+	}
+	r.buf.discard()
+	r.t.Errorf("suss: health check %v failed: %s.\n"+
+		"Add suss.%v to Settings.SuppressHealthChecks to disable this check.",
+		h, fmt.Sprintf(format, args...), h)
+	r.logSeed()
+	r.t.FailNow()
+}
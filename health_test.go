@@ -0,0 +1,125 @@
+package suss
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSuppressHealthChecks(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{
+		MaxExamples:          100,
+		GenerateTime:         100 * time.Millisecond,
+		SuppressHealthChecks: HealthInvalid,
+	})
+	s.Run(func() {
+		if s.Uint64() != 0 {
+			Invalid()
+		}
+	})
+	if st := s.Stats(); st.Invalid < healthInvalid {
+		t.Fatalf("only %d invalid examples", st.Invalid)
+	}
+}
+
+func TestHealthyInvalid(t *testing.T) {
+	// a few invalid examples are fine
+	s := newTest(t).WithSettings(Settings{MaxExamples: 100})
+	s.Run(func() {
+		if s.IntRange(0, 3) == 0 {
+			Invalid()
+		}
+	})
+}
+
+func TestSlowTestIsHealthy(t *testing.T) {
+	// time spent in the test function, even inside
+	// a Draw, is not time spent generating data
+	s := newTest(t).WithSettings(Settings{MaxExamples: 5, GenerateTime: 20 * time.Millisecond})
+	s.Run(func() {
+		s.Draw(Slice(func() {
+			s.Bool()
+			time.Sleep(time.Millisecond)
+		}))
+		time.Sleep(10 * time.Millisecond)
+	})
+}
+
+// slowGen takes a long time to sample its data
+type slowGen struct{}
+
+func (slowGen) Fill(d Data) {
+	d.Draw(1, func(r *rand.Rand, n int) []byte {
+		time.Sleep(50 * time.Millisecond)
+		return Uniform(r, n)
+	})
+}
+
+func TestHealthChecks(t *testing.T) {
+	calls := 0
+	for _, tt := range []struct {
+		name     string
+		check    HealthCheck
+		settings Settings
+		f        func(s *Runner)
+	}{
+		{
+			name:  "invalid",
+			check: HealthInvalid,
+			f: func(s *Runner) {
+				if s.Uint64() != 0 {
+					Invalid()
+				}
+			},
+		},
+		{
+			name:     "overrun",
+			check:    HealthOverrun,
+			settings: Settings{MaxSize: 64},
+			f: func(s *Runner) {
+				s.Draw(&StringGen{Min: 100})
+			},
+		},
+		{
+			name:     "slow",
+			check:    HealthSlow,
+			settings: Settings{GenerateTime: 200 * time.Millisecond},
+			f: func(s *Runner) {
+				s.Draw(slowGen{})
+			},
+		},
+		{
+			name:  "nondeterministic",
+			check: HealthNondeterministic,
+			f: func(s *Runner) {
+				// fails every other time it is called
+				calls++
+				if s.Bool() || calls%2 == 1 {
+					s.Fatalf("odd call")
+				}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if !inSubprocess() {
+				out, failed := runSubprocess(t)
+				if !failed {
+					t.Fatalf("test did not fail:\n%s", out)
+				}
+				want := "health check " + tt.check.String() + " failed"
+				if !strings.Contains(out, want) {
+					t.Fatalf("output does not contain %q:\n%s", want, out)
+				}
+				if !strings.Contains(out, "-suss.seed=") {
+					t.Fatalf("seed not reported:\n%s", out)
+				}
+				return
+			}
+			s := newTest(t).WithSettings(tt.settings)
+			s.Run(func() {
+				tt.f(s)
+			})
+		})
+	}
+}
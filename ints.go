@@ -0,0 +1,367 @@
+package suss
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+// The integer generators all map a range of integers onto
+// indices. Index 0 is the simplest value in the range, which
+// is 0 if the range contains it and the bound closest to 0
+// otherwise. Higher indices move away from the simplest value,
+// alternating between positive and negative values, so that
+// shrinking the index moves towards 0 regardless of sign.
+
+// drawIndex draws an index in the range [0, n).
+// n == 0 means the whole uint64 range.
+// If smp is non-nil, it is used to sample indices,
+// otherwise indices are sampled uniformly.
+//
+// Out of range draws wrap around instead of being invalid.
+func drawIndex(d Data, n uint64, smp func(r *rand.Rand) uint64) uint64 {
+	nbytes := 8
+	if n != 0 {
+		nbytes = (bits.Len64(n-1) + 7) / 8
+	}
+	byt := d.Draw(nbytes, func(r *rand.Rand, _ int) []byte {
+		var k uint64
+		if smp != nil {
+			k = smp(r)
+		} else {
+			k = r.Uint64()
+			if n != 0 {
+				k %= n
+			}
+		}
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], k)
+		return b[8-nbytes:]
+	})
+	var b [8]byte
+	copy(b[8-nbytes:], byt)
+	k := binary.BigEndian.Uint64(b[:])
+	if n != 0 {
+		k %= n
+	}
+	return k
+}
+
+// intRange is the range of integers [lo, hi].
+type intRange struct {
+	lo, hi int64
+}
+
+// size returns the number of integers in the range.
+// The full int64 range has size 0.
+func (ir intRange) size() uint64 {
+	return uint64(ir.hi) - uint64(ir.lo) + 1
+}
+
+// mags returns the largest magnitudes on the positive
+// and negative side for ranges that contain 0.
+func (ir intRange) mags() (pos, neg uint64) {
+	return uint64(ir.hi), uint64(-(ir.lo + 1)) + 1
+}
+
+// value returns the integer for an index.
+func (ir intRange) value(k uint64) int64 {
+	if ir.lo >= 0 {
+		return int64(uint64(ir.lo) + k)
+	}
+	if ir.hi <= 0 {
+		return int64(uint64(ir.hi) - k)
+	}
+	pos, neg := ir.mags()
+	m := pos
+	if neg < m {
+		m = neg
+	}
+	switch {
+	case k == 0:
+		return 0
+	case k <= 2*m && k%2 == 1:
+		return int64((k + 1) / 2)
+	case k <= 2*m:
+		return -int64(k / 2)
+	case pos > neg:
+		return int64(k - m)
+	default:
+		return -int64(k - m)
+	}
+}
+
+// index returns the index for an integer in the range.
+func (ir intRange) index(v int64) uint64 {
+	if ir.lo >= 0 {
+		return uint64(v) - uint64(ir.lo)
+	}
+	if ir.hi <= 0 {
+		return uint64(ir.hi) - uint64(v)
+	}
+	if v == 0 {
+		return 0
+	}
+	pos, neg := ir.mags()
+	m := pos
+	if neg < m {
+		m = neg
+	}
+	a := uint64(v)
+	if v < 0 {
+		a = uint64(-(v + 1)) + 1
+	}
+	switch {
+	case a > m:
+		return a + m
+	case v > 0:
+		return 2*a - 1
+	default:
+		return 2 * a
+	}
+}
+
+// sample returns an index, biased towards the boundaries
+// of the range and the values around 0.
+func (ir intRange) sample(r *rand.Rand) uint64 {
+	if r.Intn(2) == 0 {
+		var nasty []int64
+		for _, v := range []int64{ir.lo, ir.hi, 0, 1, -1, ir.lo + 1, ir.hi - 1} {
+			if v >= ir.lo && v <= ir.hi {
+				nasty = append(nasty, v)
+			}
+		}
+		return ir.index(nasty[r.Intn(len(nasty))])
+	}
+	k := r.Uint64()
+	if n := ir.size(); n != 0 {
+		k %= n
+	}
+	return k
+}
+
+func drawInt(d Data, lo, hi int64) int64 {
+	if lo > hi {
+		panic("invalid integer range")
+	}
+	ir := intRange{lo, hi}
+	return ir.value(drawIndex(d, ir.size(), ir.sample))
+}
+
+// uintRange is the range of unsigned integers [lo, hi].
+// Indices are simply the offset from lo.
+type uintRange struct {
+	lo, hi uint64
+}
+
+func (ur uintRange) sample(r *rand.Rand) uint64 {
+	n := ur.hi - ur.lo + 1
+	if r.Intn(2) == 0 {
+		nasty := []uint64{0, n - 1, 1, n - 2}
+		k := nasty[r.Intn(len(nasty))]
+		if n == 0 || k < n {
+			return k
+		}
+		return 0
+	}
+	k := r.Uint64()
+	if n != 0 {
+		k %= n
+	}
+	return k
+}
+
+func drawUint(d Data, lo, hi uint64) uint64 {
+	if lo > hi {
+		panic("invalid integer range")
+	}
+	ur := uintRange{lo, hi}
+	return lo + drawIndex(d, hi-lo+1, ur.sample)
+}
+
+// IntRangeGen generates an int64 in the range [Lo, Hi].
+// Unlike Int63nGen, every draw produces a value in the
+// range, so Invalid is never called.
+// Values shrink towards 0, or towards the bound closest
+// to 0 if the range does not contain 0.
+// After Fill, the value can be read from Value.
+type IntRangeGen struct {
+	Value int64
+	Lo    int64
+	Hi    int64
+}
+
+func (i *IntRangeGen) Fill(d Data) {
+	i.Value = drawInt(d, i.Lo, i.Hi)
+}
+
+// UintRangeGen generates a uint64 in the range [Lo, Hi].
+// Values shrink towards Lo.
+// After Fill, the value can be read from Value.
+type UintRangeGen struct {
+	Value uint64
+	Lo    uint64
+	Hi    uint64
+}
+
+func (u *UintRangeGen) Fill(d Data) {
+	u.Value = drawUint(d, u.Lo, u.Hi)
+}
+
+// IntRange is a convenience function that returns
+// an int in the range [lo, hi] from the Runner.
+func (r *Runner) IntRange(lo, hi int) int {
+	return int(r.Int64Range(int64(lo), int64(hi)))
+}
+
+// Int64Range is a convenience function that returns
+// an int64 in the range [lo, hi] from the Runner.
+func (r *Runner) Int64Range(lo, hi int64) int64 {
+	g := IntRangeGen{Lo: lo, Hi: hi}
+	r.Draw(&g)
+	return g.Value
+}
+
+// Uint64Range is a convenience function that returns
+// a uint64 in the range [lo, hi] from the Runner.
+func (r *Runner) Uint64Range(lo, hi uint64) uint64 {
+	g := UintRangeGen{Lo: lo, Hi: hi}
+	r.Draw(&g)
+	return g.Value
+}
+
+// IntGen implements a generator for int values.
+type IntGen int
+
+func (i *IntGen) Fill(d Data) {
+	*i = IntGen(drawInt(d, math.MinInt, math.MaxInt))
+}
+
+// Int is a convenience function that returns
+// an int value from the Runner.
+func (r *Runner) Int() int {
+	var i IntGen
+	r.Draw(&i)
+	return int(i)
+}
+
+// Int8Gen implements a generator for int8 values.
+type Int8Gen int8
+
+func (i *Int8Gen) Fill(d Data) {
+	*i = Int8Gen(drawInt(d, math.MinInt8, math.MaxInt8))
+}
+
+// Int8 is a convenience function that returns
+// an int8 value from the Runner.
+func (r *Runner) Int8() int8 {
+	var i Int8Gen
+	r.Draw(&i)
+	return int8(i)
+}
+
+// Int16Gen implements a generator for int16 values.
+type Int16Gen int16
+
+func (i *Int16Gen) Fill(d Data) {
+	*i = Int16Gen(drawInt(d, math.MinInt16, math.MaxInt16))
+}
+
+// Int16 is a convenience function that returns
+// a int16 value from the Runner.
+func (r *Runner) Int16() int16 {
+	var i Int16Gen
+	r.Draw(&i)
+	return int16(i)
+}
+
+// Int32Gen implements a generator for int32 values.
+type Int32Gen int32
+
+func (i *Int32Gen) Fill(d Data) {
+	*i = Int32Gen(drawInt(d, math.MinInt32, math.MaxInt32))
+}
+
+// Int32 is a convenience function that returns
+// an int32 value from the Runner.
+func (r *Runner) Int32() int32 {
+	var i Int32Gen
+	r.Draw(&i)
+	return int32(i)
+}
+
+// Int64Gen implements a generator for int64 values.
+type Int64Gen int64
+
+func (i *Int64Gen) Fill(d Data) {
+	*i = Int64Gen(drawInt(d, math.MinInt64, math.MaxInt64))
+}
+
+// Int64 is a convenience function that returns
+// an int64 value from the Runner.
+func (r *Runner) Int64() int64 {
+	var i Int64Gen
+	r.Draw(&i)
+	return int64(i)
+}
+
+// Uint8Gen implements a generator for uint8 values.
+type Uint8Gen uint8
+
+func (u *Uint8Gen) Fill(d Data) {
+	*u = Uint8Gen(drawUint(d, 0, math.MaxUint8))
+}
+
+// Uint8 is a convenience function that returns
+// a uint8 value from the Runner.
+func (r *Runner) Uint8() uint8 {
+	var u Uint8Gen
+	r.Draw(&u)
+	return uint8(u)
+}
+
+// Uint16Gen implements a generator for uint16 values.
+type Uint16Gen uint16
+
+func (u *Uint16Gen) Fill(d Data) {
+	*u = Uint16Gen(drawUint(d, 0, math.MaxUint16))
+}
+
+// Uint16 is a convenience function that returns
+// a uint16 value from the Runner.
+func (r *Runner) Uint16() uint16 {
+	var u Uint16Gen
+	r.Draw(&u)
+	return uint16(u)
+}
+
+// Uint32Gen implements a generator for uint32 values.
+type Uint32Gen uint32
+
+func (u *Uint32Gen) Fill(d Data) {
+	*u = Uint32Gen(drawUint(d, 0, math.MaxUint32))
+}
+
+// Uint32 is a convenience function that returns
+// a uint32 value from the Runner.
+func (r *Runner) Uint32() uint32 {
+	var u Uint32Gen
+	r.Draw(&u)
+	return uint32(u)
+}
+
+// Uint64Gen implements a generator for uint64 values.
+type Uint64Gen uint64
+
+func (u *Uint64Gen) Fill(d Data) {
+	*u = Uint64Gen(drawUint(d, 0, math.MaxUint64))
+}
+
+// Uint64 is a convenience function that returns
+// a uint64 value from the Runner.
+func (r *Runner) Uint64() uint64 {
+	var u Uint64Gen
+	r.Draw(&u)
+	return uint64(u)
+}
@@ -0,0 +1,51 @@
+package suss
+
+import (
+	"testing"
+)
+
+func TestIntRangeIndex(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		lo, hi := s.Int64(), s.Int64()
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		ir := intRange{lo, hi}
+		k := s.Uint64()
+		if n := ir.size(); n != 0 {
+			k %= n
+		}
+		v := ir.value(k)
+		if v < lo || v > hi {
+			s.Fatalf("value out of range [%v, %v]: index %v, value %v", lo, hi, k, v)
+		}
+		if got := ir.index(v); got != k {
+			s.Fatalf("range [%v, %v]: index %v gives value %v, which gives index %v", lo, hi, k, v, got)
+		}
+		if k > 0 && lo <= 0 && hi >= 0 && abs64(ir.value(k-1)) > abs64(v) {
+			s.Fatalf("range [%v, %v]: index %v further from 0 than index %v", lo, hi, k-1, k)
+		}
+	})
+}
+
+func abs64(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+func TestIntShrinksTowardsZero(t *testing.T) {
+	// the simplest possible buffer should give the simplest value
+	for _, tt := range []struct{ lo, hi, want int64 }{
+		{-10, 10, 0},
+		{5, 10, 5},
+		{-10, -5, -5},
+	} {
+		b := bufFromBytes([]byte{0})
+		if got := drawInt(b, tt.lo, tt.hi); got != tt.want {
+			t.Errorf("simplest value in [%v, %v] = %v, want %v", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
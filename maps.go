@@ -0,0 +1,77 @@
+package suss
+
+// MapGen generates maps with keys drawn from Keys and values
+// drawn from Values. Duplicate keys are redrawn, so Min and Max
+// bound the number of entries in the map.
+// Avg, Min and Max work like they do for SliceGen.
+//
+// Every entry is its own example, so maps shrink by deleting entries.
+type MapGen[K comparable, V any] struct {
+	Keys   Gen[K]
+	Values Gen[V]
+	Avg    int
+	Min    int
+	Max    int
+}
+
+// MapOf returns a generator for maps with keys drawn
+// from keys and values drawn from values.
+func MapOf[K comparable, V any](keys Gen[K], values Gen[V]) *MapGen[K, V] {
+	sl := Slice(nil)
+	return &MapGen[K, V]{
+		Keys:   keys,
+		Values: values,
+		Avg:    sl.Avg,
+		Min:    sl.Min,
+		Max:    sl.Max,
+	}
+}
+
+func (g *MapGen[K, V]) Generate(d Data) map[K]V {
+	m := make(map[K]V)
+	fillSlice(d, g.Avg, g.Min, g.Max, func() bool {
+		k := DrawFrom(d, g.Keys)
+		if _, ok := m[k]; ok {
+			// don't bother drawing a value
+			// for a duplicate key
+			return false
+		}
+		m[k] = DrawFrom(d, g.Values)
+		return true
+	})
+	return m
+}
+
+// SetGen generates sets of elements drawn from Elem.
+// Sets are represented as maps with empty struct values.
+// Avg, Min and Max work like they do for MapGen.
+type SetGen[K comparable] struct {
+	Elem Gen[K]
+	Avg  int
+	Min  int
+	Max  int
+}
+
+// SetOf returns a generator for sets with elements drawn from elem.
+func SetOf[K comparable](elem Gen[K]) *SetGen[K] {
+	sl := Slice(nil)
+	return &SetGen[K]{
+		Elem: elem,
+		Avg:  sl.Avg,
+		Min:  sl.Min,
+		Max:  sl.Max,
+	}
+}
+
+func (g *SetGen[K]) Generate(d Data) map[K]struct{} {
+	set := make(map[K]struct{})
+	fillSlice(d, g.Avg, g.Min, g.Max, func() bool {
+		k := DrawFrom(d, g.Elem)
+		if _, ok := set[k]; ok {
+			return false
+		}
+		set[k] = struct{}{}
+		return true
+	})
+	return set
+}
@@ -0,0 +1,54 @@
+package suss
+
+import (
+	"testing"
+)
+
+func TestUnique(t *testing.T) {
+	// a small key space makes duplicates common
+	small := GenFunc[int](func(d Data) int {
+		return int(drawInt(d, 0, 5))
+	})
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		m := MapOf(small, small)
+		m.Min, m.Max = 3, 5
+		if mv := Draw(s, m); len(mv) < 3 || len(mv) > 5 {
+			s.Fatalf("map has %v entries, want between 3 and 5", len(mv))
+		}
+		set := SetOf(small)
+		set.Min = 2
+		if sv := Draw(s, set); len(sv) < 2 {
+			s.Fatalf("set has %v elements, want at least 2", len(sv))
+		}
+
+		sl := SliceOf(small)
+		sl.Key = func(i int) interface{} { return i / 2 }
+		checkDistinct(s, Draw(s, sl))
+
+		var u []int
+		ug := UniqueSlice(func() interface{} {
+			return Draw(s, small)
+		}, func(v interface{}) interface{} {
+			return v.(int) / 2
+		}, func(v interface{}) {
+			u = append(u, v.(int))
+		})
+		ug.Min = 1
+		s.Draw(ug)
+		if len(u) < 1 {
+			s.Fatalf("unique slice too short: %v", u)
+		}
+		checkDistinct(s, u)
+	})
+}
+
+func checkDistinct(s *Runner, sl []int) {
+	seen := make(map[int]bool)
+	for _, v := range sl {
+		if seen[v/2] {
+			s.Fatalf("duplicate key %v in %v", v/2, sl)
+		}
+		seen[v/2] = true
+	}
+}
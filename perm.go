@@ -0,0 +1,61 @@
+package suss
+
+// PermutationGen generates permutations of the integers [0, N).
+// After Fill, the permutation can be read from Value.
+//
+// A permutation is drawn as a sequence of swaps, like a
+// Fisher-Yates shuffle. Position i is swapped with a position
+// at an offset from it, and an offset of 0 leaves it in place,
+// so permutations shrink towards the identity permutation.
+type PermutationGen struct {
+	Value []int
+	N     int
+}
+
+// Permutation returns a generator for permutations of [0, n).
+func Permutation(n int) *PermutationGen {
+	if n < 0 {
+		panic("invalid permutation size")
+	}
+	return &PermutationGen{N: n}
+}
+
+func (g *PermutationGen) Fill(d Data) {
+	g.Value = drawPerm(d, g.N)
+}
+
+func drawPerm(d Data, n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	for i := 0; i < n-1; i++ {
+		j := i + int(drawIndex(d, uint64(n-i), nil))
+		p[i], p[j] = p[j], p[i]
+	}
+	return p
+}
+
+// Perm is a convenience function that returns
+// a permutation of [0, n) from the Runner,
+// like rand.Perm.
+func (r *Runner) Perm(n int) []int {
+	g := Permutation(n)
+	r.Draw(g)
+	return g.Value
+}
+
+// Shuffle returns a generator for orderings of the elements
+// of s. Every value is a new slice, s is never modified.
+// Orderings shrink towards the order of s.
+func Shuffle[T any](s []T) Gen[[]T] {
+	s = append([]T(nil), s...)
+	return GenFunc[[]T](func(d Data) []T {
+		p := drawPerm(d, len(s))
+		v := make([]T, len(s))
+		for i, j := range p {
+			v[i] = s[j]
+		}
+		return v
+	})
+}
@@ -0,0 +1,35 @@
+package suss
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestPermutation(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		n := s.IntRange(0, 20)
+		p := s.Perm(n)
+		sorted := append([]int(nil), p...)
+		sort.Ints(sorted)
+		for i, v := range sorted {
+			if v != i {
+				s.Fatalf("%v is not a permutation of [0, %d)", p, n)
+			}
+		}
+	})
+}
+
+func TestShuffleSimplest(t *testing.T) {
+	in := []string{"a", "b", "c", "d"}
+	v := DrawFrom(bufFromBytes(make([]byte, 10)), Shuffle(in))
+	for i := range in {
+		if v[i] != in[i] {
+			t.Fatalf("simplest shuffle = %v, want %v", v, in)
+		}
+	}
+	v[0] = "z"
+	if in[0] != "a" {
+		t.Fatal("shuffle modified its input")
+	}
+}
@@ -0,0 +1,83 @@
+package suss
+
+// Recursive returns a generator for tree-shaped values, such as
+// ASTs or nested documents. Every node of the tree is either a
+// leaf drawn from base, or a branch drawn from the generator
+// extend returns. extend is given a generator for child nodes,
+// so that branches can contain any number of subtrees:
+//
+//	type Tree struct{ Children []*Tree }
+//	leaf := suss.Just(&Tree{})
+//	tree := suss.Recursive(leaf, func(child suss.Gen[*Tree]) suss.Gen[*Tree] {
+//		return suss.Map(suss.SliceOf(child), func(c []*Tree) *Tree {
+//			return &Tree{Children: c}
+//		})
+//	}, 50)
+//
+// Branches become less likely deeper in the tree, and at most
+// maxLeaves leaves are drawn from base. Once that many have been
+// drawn, the rest of the tree is drawn from zeros, the simplest data,
+// instead of the test data, so branches stop asking for more children
+// and no new branches are started. A branch that needs more children
+// than that, such as a slice with a minimum length, makes the data
+// invalid, like Invalid. This keeps trees from growing without bound
+// and running out of data.
+//
+// Every node draws a choice between leaf and branch before drawing
+// its value, with a leaf being the simpler choice. This lets the
+// shrinker replace a subtree with a leaf, as well as delete it.
+func Recursive[T any](base Gen[T], extend func(Gen[T]) Gen[T], maxLeaves int) Gen[T] {
+	return &recursiveGen[T]{
+		base:      base,
+		extend:    extend,
+		maxLeaves: maxLeaves,
+	}
+}
+
+type recursiveGen[T any] struct {
+	base      Gen[T]
+	extend    func(Gen[T]) Gen[T]
+	maxLeaves int
+}
+
+func (g *recursiveGen[T]) Generate(d Data) T {
+	// the budget is per tree, so the node generator
+	// is built anew every time
+	bd := &budgetData{Data: d}
+	leaves := 0
+	depth := 0
+	var branch Gen[T]
+	node := GenFunc[T](func(d Data) T {
+		if leaves >= g.maxLeaves {
+			Invalid()
+		}
+		if biasBool(d, 1/float64(depth+2)) {
+			depth++
+			v := DrawFrom(d, branch)
+			depth--
+			return v
+		}
+		v := DrawFrom(d, g.base)
+		leaves++
+		if leaves == g.maxLeaves {
+			bd.spent = true
+		}
+		return v
+	})
+	branch = g.extend(node)
+	return DrawFrom(Data(bd), Gen[T](node))
+}
+
+// budgetData is the Data a recursive value is drawn from.
+// Once the leaf budget is spent, it only returns zeros.
+type budgetData struct {
+	Data
+	spent bool
+}
+
+func (d *budgetData) Draw(n int, smp Sample) []byte {
+	if d.spent {
+		return make([]byte, n)
+	}
+	return d.Data.Draw(n, smp)
+}
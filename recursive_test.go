@@ -0,0 +1,52 @@
+package suss
+
+import (
+	"testing"
+)
+
+type recTree struct {
+	children []*recTree
+}
+
+func (t *recTree) leaves() int {
+	if len(t.children) == 0 {
+		return 1
+	}
+	n := 0
+	for _, c := range t.children {
+		n += c.leaves()
+	}
+	return n
+}
+
+func TestRecursive(t *testing.T) {
+	leaf := GenFunc[*recTree](func(d Data) *recTree {
+		return &recTree{}
+	})
+	// the default slice has 50 children on average,
+	// far more than the budget allows
+	for _, max := range []int{3, int(^uint(0) >> 1)} {
+		tree := Recursive(leaf, func(child Gen[*recTree]) Gen[*recTree] {
+			children := SliceOf(child)
+			children.Min, children.Max = 1, max
+			return Map(children, func(c []*recTree) *recTree {
+				return &recTree{children: c}
+			})
+		}, 10)
+		s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+		s.Run(func() {
+			tr := Draw(s, tree)
+			if n := tr.leaves(); n > 10 {
+				s.Fatalf("tree has %v leaves, want at most 10", n)
+			}
+		})
+		// running out of leaves stops the tree from
+		// growing, instead of making the data invalid
+		if st := s.Stats(); st.Invalid != 0 {
+			t.Errorf("max %d: %d invalid trees", max, st.Invalid)
+		}
+		if v := DrawFrom(bufFromBytes([]byte{0}), tree); len(v.children) != 0 {
+			t.Fatalf("simplest tree is not a leaf: %+v", v)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package suss
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AnyGen fills a value of any type, using reflection.
+// It is created with Any.
+type AnyGen struct {
+	v reflect.Value
+}
+
+// Any returns a generator that fills the value ptr points to.
+//
+// Booleans, numbers, strings, slices, arrays, maps, pointers
+// and structs are filled recursively. Unexported struct fields,
+// channels, functions and interfaces are left alone.
+// Types that implement Generator, either directly or through
+// a pointer, are filled with their own Fill method.
+//
+// Struct fields can be tagged to constrain their values:
+//
+//	type Point struct {
+//		X    int     `suss:"min=0,max=10"`
+//		Name string  `suss:"max=5"`
+//		Tags []string `suss:"min=1"`
+//		Next *Point  `suss:"-"`
+//	}
+//
+// min and max bound numbers, and the length of strings,
+// slices and maps. Bounds that don't fit the type of the
+// field panic. A tag of "-" leaves the field alone.
+func Any(ptr interface{}) *AnyGen {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		panic("suss: Any requires a non-nil pointer")
+	}
+	return &AnyGen{v: v.Elem()}
+}
+
+func (g *AnyGen) Fill(d Data) {
+	fillValue(d, g.v, tagOptions{})
+}
+
+// Fill is a convenience function that fills the value
+// ptr points to with data from the Runner. See Any.
+func (r *Runner) Fill(ptr interface{}) {
+	r.Draw(Any(ptr))
+}
+
+var (
+	generatorType = reflect.TypeOf((*Generator)(nil)).Elem()
+	timeType      = reflect.TypeOf(time.Time{})
+	bigIntType    = reflect.TypeOf(big.Int{})
+	bigRatType    = reflect.TypeOf(big.Rat{})
+	bigFloatType  = reflect.TypeOf(big.Float{})
+)
+
+// tagOptions are the options in a suss struct tag.
+// Unset options are empty strings, since their
+// interpretation depends on the kind of the field.
+type tagOptions struct {
+	min, max string
+}
+
+func parseTag(tag string) (tagOptions, bool) {
+	var opts tagOptions
+	if tag == "-" {
+		return opts, false
+	}
+	if tag == "" {
+		return opts, true
+	}
+	for _, opt := range strings.Split(tag, ",") {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 {
+			panic("suss: invalid struct tag option " + strconv.Quote(opt))
+		}
+		switch kv[0] {
+		case "min":
+			opts.min = kv[1]
+		case "max":
+			opts.max = kv[1]
+		default:
+			panic("suss: unknown struct tag option " + strconv.Quote(kv[0]))
+		}
+	}
+	return opts, true
+}
+
+// intBounds returns the bounds set by the options,
+// defaulting to lo and hi, which are the bounds of the kind
+// being filled. Bounds outside of them panic, since
+// setting the value would wrap around.
+func (o tagOptions) intBounds(lo, hi int64) (int64, int64) {
+	parse := func(s string, def int64) int64 {
+		if s == "" {
+			return def
+		}
+		i, err := strconv.ParseInt(s, 0, 64)
+		if err != nil {
+			panic("suss: invalid struct tag bound: " + err.Error())
+		}
+		if i < lo || i > hi {
+			panic(fmt.Sprintf("suss: struct tag bound %s outside [%d, %d]", s, lo, hi))
+		}
+		return i
+	}
+	return parse(o.min, lo), parse(o.max, hi)
+}
+
+func (o tagOptions) uintBounds(lo, hi uint64) (uint64, uint64) {
+	parse := func(s string, def uint64) uint64 {
+		if s == "" {
+			return def
+		}
+		i, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			panic("suss: invalid struct tag bound: " + err.Error())
+		}
+		if i < lo || i > hi {
+			panic(fmt.Sprintf("suss: struct tag bound %s outside [%d, %d]", s, lo, hi))
+		}
+		return i
+	}
+	return parse(o.min, lo), parse(o.max, hi)
+}
+
+// floatBounds returns the bounds set by the options.
+// Finite bounds larger than max panic.
+func (o tagOptions) floatBounds(max float64) (float64, float64) {
+	parse := func(s string, def float64) float64 {
+		if s == "" {
+			return def
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			panic("suss: invalid struct tag bound: " + err.Error())
+		}
+		if !math.IsInf(f, 0) && math.Abs(f) > max {
+			panic(fmt.Sprintf("suss: struct tag bound %s outside [%v, %v]", s, -max, max))
+		}
+		return f
+	}
+	return parse(o.min, math.Inf(-1)), parse(o.max, math.Inf(1))
+}
+
+// lengths sets the length bounds of a slice or string
+// generator, leaving them alone if the options are unset.
+func (o tagOptions) lengths(min, max *int) {
+	lo, hi := o.intBounds(0, math.MaxInt32)
+	if o.min != "" {
+		*min = int(lo)
+	}
+	if o.max != "" {
+		*max = int(hi)
+	}
+}
+
+func fillValue(d Data, v reflect.Value, opts tagOptions) {
+	d.StartExample()
+	defer d.EndExample()
+	if v.CanAddr() && v.Addr().Type().Implements(generatorType) {
+		v.Addr().Interface().(Generator).Fill(d)
+		return
+	}
+	t := v.Type()
+	// these types only have unexported fields,
+	// but there are generators for them
+	switch t {
+	case timeType:
+		var g TimeGen
+		g.Fill(d)
+		v.Set(reflect.ValueOf(g.Value))
+		return
+	case bigIntType:
+		var g BigIntGen
+		g.Fill(d)
+		v.Set(reflect.ValueOf(g.Value).Elem())
+		return
+	case bigRatType:
+		var g BigRatGen
+		g.Fill(d)
+		v.Set(reflect.ValueOf(g.Value).Elem())
+		return
+	case bigFloatType:
+		var g BigFloatGen
+		g.Fill(d)
+		v.Set(reflect.ValueOf(g.Value).Elem())
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(drawIndex(d, 2, nil) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := uint(t.Bits())
+		lo, hi := opts.intBounds(-1<<(bits-1), 1<<(bits-1)-1)
+		v.SetInt(drawInt(d, lo, hi))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits := uint(t.Bits())
+		lo, hi := opts.uintBounds(0, math.MaxUint64>>(64-bits))
+		v.SetUint(drawUint(d, lo, hi))
+	case reflect.Float32, reflect.Float64:
+		if opts.min != "" || opts.max != "" {
+			max := math.MaxFloat64
+			if t.Kind() == reflect.Float32 {
+				max = math.MaxFloat32
+			}
+			g := Float64Range(opts.floatBounds(max))
+			g.Fill(d)
+			v.SetFloat(g.Value)
+		} else if t.Kind() == reflect.Float32 {
+			var f Float32Gen
+			f.Fill(d)
+			v.SetFloat(float64(f))
+		} else {
+			var f Float64Gen
+			f.Fill(d)
+			v.SetFloat(float64(f))
+		}
+	case reflect.Complex64, reflect.Complex128:
+		var re, im Float64Gen
+		re.Fill(d)
+		im.Fill(d)
+		v.SetComplex(complex(float64(re), float64(im)))
+	case reflect.String:
+		var g StringGen
+		opts.lengths(&g.Min, &g.Max)
+		g.Fill(d)
+		v.SetString(g.Value)
+	case reflect.Slice:
+		s := reflect.MakeSlice(t, 0, 0)
+		sl := Slice(func() {
+			e := reflect.New(t.Elem()).Elem()
+			fillValue(d, e, tagOptions{})
+			s = reflect.Append(s, e)
+		})
+		sl.Avg = 5
+		opts.lengths(&sl.Min, &sl.Max)
+		sl.Fill(d)
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fillValue(d, v.Index(i), tagOptions{})
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(t)
+		sl := Slice(nil)
+		sl.Avg = 5
+		opts.lengths(&sl.Min, &sl.Max)
+		// redraw duplicate keys like MapGen does
+		fillSlice(d, sl.Avg, sl.Min, sl.Max, func() bool {
+			k := reflect.New(t.Key()).Elem()
+			fillValue(d, k, tagOptions{})
+			if m.MapIndex(k).IsValid() {
+				return false
+			}
+			e := reflect.New(t.Elem()).Elem()
+			fillValue(d, e, tagOptions{})
+			m.SetMapIndex(k, e)
+			return true
+		})
+		v.Set(m)
+	case reflect.Ptr:
+		// nil is simpler than a value
+		if drawIndex(d, 2, nil) == 0 {
+			v.Set(reflect.Zero(t))
+			return
+		}
+		e := reflect.New(t.Elem())
+		fillValue(d, e.Elem(), opts)
+		v.Set(e)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Field(i)
+			if !f.CanSet() {
+				continue
+			}
+			fopts, ok := parseTag(t.Field(i).Tag.Get("suss"))
+			if !ok {
+				continue
+			}
+			fillValue(d, f, fopts)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package suss
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+type anyPoint struct {
+	X    int      `suss:"min=-5,max=10"`
+	Y    uint8    `suss:"min=3"`
+	F    float64  `suss:"min=0,max=1"`
+	Name string   `suss:"max=4"`
+	Tags []string `suss:"min=1,max=3"`
+	Skip int      `suss:"-"`
+	Next *anyPoint
+	Arr  [2]bool
+	M    map[int8]string
+	Gen  evenGen
+
+	unexported int
+}
+
+// evenGen checks that types implementing Generator are filled
+// with their own Fill method
+type evenGen int
+
+func (e *evenGen) Fill(d Data) {
+	*e = evenGen(drawInt(d, 0, 100) * 2)
+}
+
+func TestAny(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		var p anyPoint
+		s.Fill(&p)
+		for q := &p; q != nil; q = q.Next {
+			if q.X < -5 || q.X > 10 || q.Y < 3 || q.F < 0 || q.F > 1 {
+				s.Fatalf("value out of bounds: %+v", q)
+			}
+			if utf8.RuneCountInString(q.Name) > 4 || len(q.Tags) < 1 || len(q.Tags) > 3 {
+				s.Fatalf("length out of bounds: %+v", q)
+			}
+			if q.Skip != 0 || q.unexported != 0 {
+				s.Fatalf("skipped field filled: %+v", q)
+			}
+			if q.Gen%2 != 0 {
+				s.Fatalf("Fill method not used: %+v", q)
+			}
+		}
+	})
+}
+
+func TestAnyTagRange(t *testing.T) {
+	for _, v := range []interface{}{
+		&struct {
+			X int8 `suss:"max=1000"`
+		}{},
+		&struct {
+			X uint8 `suss:"max=300"`
+		}{},
+		&struct {
+			X int16 `suss:"min=-40000"`
+		}{},
+		&struct {
+			X float32 `suss:"max=1e40"`
+		}{},
+		&struct {
+			X []int `suss:"min=-1"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				err, _ := recover().(string)
+				if !strings.Contains(err, "struct tag bound") {
+					t.Errorf("%T: recovered %q, want out of range panic", v, err)
+				}
+			}()
+			Any(v).Fill(bufFromBytes(make([]byte, 64)))
+		}()
+	}
+}
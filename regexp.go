@@ -0,0 +1,136 @@
+package suss
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// RegexpGen generates strings that match a regular expression.
+// After Fill, the value can be read from Value.
+type RegexpGen struct {
+	Value string
+
+	re   *syntax.Regexp
+	full *regexp.Regexp
+}
+
+// Regexp returns a generator for strings that match the whole
+// of pattern, which uses the same syntax as the regexp package.
+// It panics if the pattern does not compile.
+//
+// Every sub-expression is drawn as its own example, so that
+// strings shrink towards the shortest and simplest match:
+// repetitions towards their minimum count, alternations towards
+// their first branch and character classes towards their lowest rune.
+//
+// Assertions like \b and ^ in the middle of a pattern are
+// not generated directly. Strings that end up not matching
+// because of them are marked invalid.
+func Regexp(pattern string) *RegexpGen {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		panic("suss: Regexp(" + pattern + "): " + err.Error())
+	}
+	return &RegexpGen{
+		re:   re,
+		full: regexp.MustCompile(`^(?:` + pattern + `)\z`),
+	}
+}
+
+func (g *RegexpGen) Fill(d Data) {
+	var b strings.Builder
+	genRegexp(d, g.re, &b)
+	g.Value = b.String()
+	if !g.full.MatchString(g.Value) {
+		Invalid()
+	}
+}
+
+var (
+	anyCharSet = newRuneSet(nil, nil)
+	// like the default set, but without newline
+	anyCharNotNLSet = &runeSet{
+		segments: []runeSegment{
+			{'0', 0x7f, 1},
+			{0, '\n' - 1, 1},
+			{'\n' + 1, '0' - 1, 1},
+			{0x80, 0xd7ff, 1},
+			{0xe000, unicode.MaxRune, 1},
+		},
+		n: anyCharSet.n - 1,
+	}
+)
+
+func genRegexp(d Data, re *syntax.Regexp, b *strings.Builder) {
+	d.StartExample()
+	defer d.EndExample()
+	switch re.Op {
+	case syntax.OpNoMatch:
+		Invalid()
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		// assertions match the empty string,
+		// Fill checks that they hold
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 {
+				c = drawFold(d, c)
+			}
+			b.WriteRune(c)
+		}
+	case syntax.OpCharClass:
+		rs := &runeSet{}
+		for i := 0; i < len(re.Rune); i += 2 {
+			rs.addSegment(runeSegment{re.Rune[i], re.Rune[i+1], 1})
+		}
+		if rs.n == 0 {
+			Invalid()
+		}
+		b.WriteRune(rs.draw(d))
+	case syntax.OpAnyCharNotNL:
+		b.WriteRune(anyCharNotNLSet.draw(d))
+	case syntax.OpAnyChar:
+		b.WriteRune(anyCharSet.draw(d))
+	case syntax.OpCapture:
+		genRegexp(d, re.Sub[0], b)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		sl := Slice(func() {
+			genRegexp(d, re.Sub[0], b)
+		})
+		sl.Avg = 3
+		switch re.Op {
+		case syntax.OpPlus:
+			sl.Min = 1
+		case syntax.OpQuest:
+			sl.Max = 1
+		case syntax.OpRepeat:
+			sl.Min = re.Min
+			if re.Max >= 0 {
+				sl.Max = re.Max
+			}
+		}
+		sl.Fill(d)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			genRegexp(d, sub, b)
+		}
+	case syntax.OpAlternate:
+		i := drawIndex(d, uint64(len(re.Sub)), nil)
+		genRegexp(d, re.Sub[i], b)
+	default:
+		panic("unknown regexp op " + re.Op.String())
+	}
+}
+
+// drawFold draws one of the runes that are equivalent to c
+// under case folding. It shrinks towards c itself.
+func drawFold(d Data, c rune) rune {
+	folds := []rune{c}
+	for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+		folds = append(folds, f)
+	}
+	return folds[drawIndex(d, uint64(len(folds)), nil)]
+}
@@ -0,0 +1,26 @@
+package suss
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegexp(t *testing.T) {
+	patterns := []string{
+		`[a-z]+@[a-z]+\.(com|org)`,
+		`(?i)hello, w.rld`,
+		`a{2,4}b*c?`,
+		`\d{3}-\d{4}`,
+		`[^\x00-\x7f]\pL`,
+		`\bfoo\b`,
+	}
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		pattern := patterns[s.IntRange(0, len(patterns)-1)]
+		g := Regexp(pattern)
+		s.Draw(g)
+		if !regexp.MustCompile(`^(?:` + pattern + `)$`).MatchString(g.Value) {
+			s.Fatalf("%q does not match %q", g.Value, pattern)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package suss
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Settings control how much work a Runner does when
+// looking for and shrinking failing examples.
+//
+// A zero field means that the value is taken from
+// the settings the Runner already has.
+type Settings struct {
+	// MaxExamples is the number of valid examples to run
+	// before concluding that the test passes.
+	MaxExamples int
+
+	// GenerateTime is the time spent looking for a
+	// failing example before concluding that the test passes.
+	GenerateTime time.Duration
+
+	// ShrinkTime is the time spent shrinking a failing example.
+	// When it runs out, the simplest example found so far is reported.
+	ShrinkTime time.Duration
+
+	// MaxSize is the maximum number of bytes a single example
+	// can draw. Examples that draw more are discarded.
+	MaxSize int
+
+	// Mutations is the number of times an example is mutated
+	// before starting over with fresh random data.
+	Mutations int
+
+	// Capture selects how output from the test is captured.
+	Capture Capture
+
+	// Guidance selects how examples are picked for mutation.
+	Guidance Guidance
+
+	// SuppressHealthChecks disables health checks.
+	SuppressHealthChecks HealthCheck
+}
+
+// Capture is a way of capturing the output of a test, so that
+// only the output of the final shrunk example is shown.
+type Capture int
+
+const (
+	// CaptureStdout redirects the process-wide stdout and stderr
+	// into a file while running an example. This captures
+	// everything the test prints, but the redirection is visible
+	// to every goroutine in the process, so tests using it
+	// must not be run in parallel.
+	CaptureStdout Capture = iota + 1
+
+	// CaptureLog only captures output written with Runner.Logf
+	// and Runner.Fatalf. The output is kept in memory for each
+	// example and the output of the final example is reported
+	// with testing.T.Log. Tests using it can call t.Parallel.
+	CaptureLog
+)
+
+// Guidance is a way of picking the examples that are
+// mutated while looking for a failing example.
+type Guidance int
+
+const (
+	// GuideRandom mutates the most recent example for a while
+	// and then starts over with fresh random data.
+	GuideRandom Guidance = iota + 1
+
+	// GuideCoverage keeps the examples that cover code no
+	// earlier example did and spends about half of the fresh
+	// starts mutating one of them instead. Coverage is read
+	// with testing.Coverage, so the test binary must be built
+	// with -cover. Without it, GuideCoverage is the same
+	// as GuideRandom.
+	//
+	// Only the coverage of packages instrumented by -cover counts,
+	// see the -coverpkg flag for including more than the
+	// package under test.
+	GuideCoverage
+)
+
+// merge returns s with its zero fields replaced by the fields in def.
+func (s Settings) merge(def Settings) Settings {
+	if s.MaxExamples == 0 {
+		s.MaxExamples = def.MaxExamples
+	}
+	if s.GenerateTime == 0 {
+		s.GenerateTime = def.GenerateTime
+	}
+	if s.ShrinkTime == 0 {
+		s.ShrinkTime = def.ShrinkTime
+	}
+	if s.MaxSize == 0 {
+		s.MaxSize = def.MaxSize
+	}
+	if s.Mutations == 0 {
+		s.Mutations = def.Mutations
+	}
+	if s.Capture == 0 {
+		s.Capture = def.Capture
+	}
+	if s.Guidance == 0 {
+		s.Guidance = def.Guidance
+	}
+	if s.SuppressHealthChecks == 0 {
+		s.SuppressHealthChecks = def.SuppressHealthChecks
+	}
+	return s
+}
+
+var (
+	profileMu sync.Mutex
+	profiles  = map[string]Settings{
+		"default": {
+			MaxExamples:  10000,
+			GenerateTime: 1 * time.Second,
+			ShrinkTime:   1 * time.Minute,
+			MaxSize:      8 << 10,
+			Mutations:    10,
+			Capture:      CaptureStdout,
+			Guidance:     GuideRandom,
+		},
+		"ci": {
+			MaxExamples:  1000000,
+			GenerateTime: 1 * time.Minute,
+			ShrinkTime:   5 * time.Minute,
+		},
+	}
+)
+
+// RegisterProfile registers a named set of settings.
+// Zero fields are taken from the "default" profile.
+// Registering a profile with an existing name replaces it,
+// this includes the built-in "default" and "ci" profiles.
+//
+// The profile used by a test is selected with the -suss.profile
+// test flag or the SUSS_PROFILE environment variable.
+// Profiles should be registered before any call to NewTest,
+// e.g. in an init function or TestMain.
+func RegisterProfile(name string, s Settings) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	profiles[name] = s
+}
+
+// profile returns the settings for the named profile
+func profile(name string) (Settings, error) {
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	s, ok := profiles[name]
+	if !ok {
+		return Settings{}, fmt.Errorf("unknown profile %q", name)
+	}
+	return s.merge(profiles["default"]), nil
+}
+
+// WithSettings changes the settings used by the Runner.
+// Zero fields in s leave the current setting unchanged.
+// It returns the Runner so that it can be chained with NewTest.
+//
+//	s := suss.NewTest(t).WithSettings(suss.Settings{MaxExamples: 100})
+//
+// WithSettings must be called before Run.
+func (r *Runner) WithSettings(s Settings) *Runner {
+	r.settings = s.merge(r.settings)
+	return r
+}
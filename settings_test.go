@@ -0,0 +1,126 @@
+package suss
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxExamples(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 25, GenerateTime: time.Minute})
+	runs := 0
+	s.Run(func() {
+		s.Uint64()
+		runs++
+	})
+	if runs != 25 {
+		t.Fatalf("ran %v examples, want 25", runs)
+	}
+}
+
+func TestRegisterProfile(t *testing.T) {
+	RegisterProfile("suss-test", Settings{MaxExamples: 5})
+	s, err := profile("suss-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, _ := profile("default")
+	want := def
+	want.MaxExamples = 5
+	if s != want {
+		t.Fatalf("profile = %+v, want %+v", s, want)
+	}
+	if _, err := profile("no-such-profile"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+}
+
+func TestCaptureLogParallel(t *testing.T) {
+	if !inSubprocess() {
+		out, failed := runSubprocess(t)
+		if !failed {
+			t.Fatalf("test did not fail:\n%s", out)
+		}
+		// every subtest shows the log of its minimal example,
+		// and only that one
+		if n := strings.Count(out, "drew 10\n"); n != 3 {
+			t.Fatalf("minimal log shown %d times, want 3:\n%s", n, out)
+		}
+		if n := strings.Count(out, "drew "); n != 3 {
+			t.Fatalf("%d logs shown, want 3:\n%s", n, out)
+		}
+		// stdout is left alone, so output written
+		// directly shows up for every example
+		if n := strings.Count(out, "stdout "); n <= 3 {
+			t.Fatalf("stdout written %d times, want more than 3:\n%s", n, out)
+		}
+		return
+	}
+	redirect = func(*buffer) (func(), error) {
+		panic("output redirected with CaptureLog")
+	}
+	defer func() { redirect = redirectOutput }()
+	// parallel subtests run after their parent returns,
+	// so group them to keep redirect set until they're done
+	dir := t.TempDir()
+	t.Run("group", func(t *testing.T) {
+		for _, name := range []string{"a", "b", "c"} {
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				s := NewTest(t).WithSettings(Settings{MaxExamples: 100, Capture: CaptureLog})
+				s.db = &exampleDB{dir: filepath.Join(dir, name)}
+				s.Run(func() {
+					v := s.IntRange(0, 1000)
+					s.Logf("drew %v", v)
+					fmt.Println("stdout", v)
+					if v >= 10 {
+						s.Fatalf("too big")
+					}
+				})
+			})
+		}
+	})
+}
+
+func TestGuideCoverage(t *testing.T) {
+	// without -cover, there is no coverage to guide by,
+	// but the test should still run normally
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500, Guidance: GuideCoverage})
+	n := 0
+	s.Run(func() {
+		if s.Bool() {
+			n++
+		}
+	})
+	if n == 0 {
+		t.Fatal("no examples run")
+	}
+}
+
+func TestGuideCoverageCorpus(t *testing.T) {
+	if testing.CoverMode() == "" {
+		t.Skip("needs -cover")
+	}
+	if !inSubprocess() {
+		// code covered by earlier tests doesn't count
+		// as new, so run with fresh coverage counters
+		if out, failed := runSubprocess(t); failed {
+			t.Fatalf("guided test failed:\n%s", out)
+		}
+		return
+	}
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500, Guidance: GuideCoverage})
+	s.Run(func() {
+		// sanitizeName has a branch for
+		// every kind of character
+		sanitizeName(s.String())
+	})
+	if len(s.corpus) < 2 {
+		t.Fatalf("corpus has %d examples, want at least 2", len(s.corpus))
+	}
+	if s.corpusMutations == 0 {
+		t.Fatal("no corpus example was mutated")
+	}
+}
@@ -1,7 +1,6 @@
 package state
 
 import (
-	"fmt"
 	"reflect"
 	"sort"
 
@@ -30,15 +29,12 @@ func (s *StateMachine) Fill(d suss.Data) {
 	var i int
 	// TODO: actually care about edges
 	sli := suss.Slice(func() {
-		var u suss.Int63nGen
-		u.N = int64(len(s.transitionStrs))
-		s.runner.Draw(&u)
-		tName := s.transitionStrs[u.Value]
+		t := s.runner.IntRange(0, len(s.transitionStrs)-1)
+		tName := s.transitionStrs[t]
 		f := s.transitionFuncs[tName]
-		t := reflect.ValueOf(new(Transition))
-		f.Call([]reflect.Value{t})
+		f.Call([]reflect.Value{reflect.ValueOf(new(Transition))})
 		i++
-		fmt.Printf("step %v: %v\n", i, tName)
+		s.runner.Logf("step %v: %v", i, tName)
 		// alright, we did our transition
 		// now run the printFunc
 		p := []reflect.Value{reflect.ValueOf(new(Print))}
@@ -0,0 +1,112 @@
+package suss
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats are statistics about the examples a Runner has run.
+// They show what the generators actually produced, e.g. that
+// most of the examples were invalid or that an interesting
+// case was never reached.
+type Stats struct {
+	// Examples is the number of examples run while looking
+	// for a failure. Examples run while shrinking are not
+	// counted, they are in Shrinks.
+	Examples int
+
+	// Valid, Invalid, Overrun and Failing count the examples
+	// that passed, were marked invalid, drew more data than
+	// allowed and failed, respectively.
+	Valid   int
+	Invalid int
+	Overrun int
+	Failing int
+
+	// AvgSize is the average number of bytes drawn by an example.
+	AvgSize float64
+
+	// Shrinks is the number of examples run while shrinking.
+	Shrinks int
+
+	// GenerateTime and ShrinkTime are the time spent looking
+	// for a failure and shrinking it.
+	GenerateTime time.Duration
+	ShrinkTime   time.Duration
+
+	// Events maps every event label to the number of
+	// examples that the event happened in.
+	Events map[string]int
+
+	size     int
+	drawTime time.Duration
+}
+
+func (s *Stats) record(b *buffer) {
+	s.Examples++
+	switch b.status {
+	case statusValid:
+		s.Valid++
+	case statusInvalid:
+		s.Invalid++
+	case statusOverrun:
+		s.Overrun++
+	case statusInteresting:
+		s.Failing++
+	}
+	s.size += len(b.buf)
+	s.drawTime += b.drawTime
+	for label := range b.events {
+		if s.Events == nil {
+			s.Events = make(map[string]int)
+		}
+		s.Events[label]++
+	}
+}
+
+// Event records that something happened in the current example.
+// Examples with the same event are counted, and the counts are
+// part of the statistics.
+//
+//	if len(s) == 0 {
+//		r.Event("empty slice")
+//	}
+func (r *Runner) Event(label string) {
+	if r.buf.events == nil {
+		r.buf.events = make(map[string]bool)
+	}
+	r.buf.events[label] = true
+}
+
+// Stats returns statistics about the examples run.
+// It is usually called after Run. When the -v test
+// flag is given, Run logs the statistics.
+func (r *Runner) Stats() Stats {
+	s := r.stats
+	if s.Examples > 0 {
+		s.AvgSize = float64(s.size) / float64(s.Examples)
+	}
+	s.Events = make(map[string]int, len(r.stats.Events))
+	for label, n := range r.stats.Events {
+		s.Events[label] = n
+	}
+	return s
+}
+
+func (r *Runner) logStats() {
+	s := r.Stats()
+	r.t.Logf("suss: %d examples: %d valid, %d invalid, %d overrun, %d failing",
+		s.Examples, s.Valid, s.Invalid, s.Overrun, s.Failing)
+	r.t.Logf("suss: average example size %.1f bytes", s.AvgSize)
+	r.t.Logf("suss: %v generating, %v shrinking with %d examples",
+		s.GenerateTime.Round(time.Millisecond), s.ShrinkTime.Round(time.Millisecond), s.Shrinks)
+	labels := make([]string, 0, len(s.Events))
+	for label := range s.Events {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		n := s.Events[label]
+		r.t.Logf("suss: event %q: %d examples (%.1f%%)", label, n, 100*float64(n)/float64(s.Examples))
+	}
+}
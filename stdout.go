@@ -0,0 +1,42 @@
+package suss
+
+import (
+	"io"
+	"os"
+)
+
+// redirect is the function used to capture the output of an
+// example. Tests replace it to exercise captureMem on
+// platforms that have a better way of redirecting output.
+var redirect = redirectOutput
+
+// captureMem captures output in memory. It is used on
+// platforms where we don't know how to redirect the process
+// output and for builds with the sussmemout tag.
+//
+// Only writes that go through the os.Stdout and os.Stderr
+// variables are captured, which includes the fmt and log
+// packages. Output from child processes and code writing
+// directly to the file descriptors is not.
+func captureMem(b *buffer) (func(), error) {
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&b.stdoutMem, pr)
+		pr.Close()
+		close(done)
+	}()
+	origStdout, origStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = pw, pw
+	f := func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+		pw.Close()
+		// wait for the copy to finish so that
+		// the output is complete
+		<-done
+	}
+	return f, nil
+}
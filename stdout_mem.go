@@ -0,0 +1,10 @@
+// +build sussmemout !darwin,!dragonfly,!freebsd,!linux,!nacl,!netbsd,!openbsd,!solaris,!windows
+
+package suss
+
+// redirectOutput captures output in memory, for platforms
+// where we don't know how to redirect the process output
+// and for builds with the sussmemout tag. See captureMem.
+func redirectOutput(b *buffer) (func(), error) {
+	return captureMem(b)
+}
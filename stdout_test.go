@@ -0,0 +1,60 @@
+package suss
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCaptureMem(t *testing.T) {
+	if !inSubprocess() {
+		out, failed := runSubprocess(t)
+		if !failed {
+			t.Fatalf("test did not fail:\n%s", out)
+		}
+		// only the output of the minimal example is shown
+		for _, want := range []string{"drew 10\n", "stderr 10\n", "too big\n"} {
+			if n := strings.Count(out, want); n != 1 {
+				t.Fatalf("%q printed %d times, want once:\n%s", want, n, out)
+			}
+		}
+		if n := strings.Count(out, "drew "); n != 1 {
+			t.Fatalf("output of %d examples shown, want 1:\n%s", n, out)
+		}
+		if strings.Index(out, "drew 10") > strings.Index(out, "--- FAIL") {
+			t.Fatalf("output shown after the test finished:\n%s", out)
+		}
+		return
+	}
+	redirect = captureMem
+	defer func() { redirect = redirectOutput }()
+	s := newTest(t)
+	s.Run(func() {
+		v := s.IntRange(0, 1000)
+		fmt.Println("drew", v)
+		fmt.Fprintln(os.Stderr, "stderr", v)
+		if v >= 10 {
+			s.Fatalf("too big")
+		}
+	})
+}
+
+func TestLogfNewline(t *testing.T) {
+	if !inSubprocess() {
+		out, failed := runSubprocess(t)
+		if !failed {
+			t.Fatalf("test did not fail:\n%s", out)
+		}
+		if !strings.Contains(out, "first\nsecond\nthird\n") {
+			t.Fatalf("newlines not added once per line:\n%s", out)
+		}
+		return
+	}
+	s := newTest(t).WithSettings(Settings{Capture: CaptureStdout})
+	s.Run(func() {
+		s.Logf("first\n")
+		s.Logf("second")
+		s.Fatalf("third")
+	})
+}
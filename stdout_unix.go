@@ -1,4 +1,5 @@
 // +build darwin dragonfly freebsd linux nacl netbsd openbsd solaris
+// +build !sussmemout
 
 package suss
 
@@ -14,7 +15,7 @@ import (
 // a file so that printing during a test does not
 // spew to the terminal. This allows us to only
 // print the output from the final shrinked test
-func redirectOutput() (*os.File, func(), error) {
+func redirectOutput(b *buffer) (func(), error) {
 	// grab the FDs so that we can reestablish
 	// stdout/stderr
 	// note that since we only close FDs that already
@@ -24,11 +25,13 @@ func redirectOutput() (*os.File, func(), error) {
 	// have to do anything special to it
 	tmpfile, err := ioutil.TempFile("", "suss")
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
+	defer tmpfile.Close()
+	b.stdout = tmpfile.Name()
 	stdout, stderr, err := getCopies()
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// these dups will be not be close-on-exec,
@@ -38,7 +41,7 @@ func redirectOutput() (*os.File, func(), error) {
 	if err != nil {
 		unix.Close(stdout)
 		unix.Close(stderr)
-		return nil, nil, err
+		return nil, err
 	}
 	err = unix.Dup2(int(tmpfile.Fd()), int(os.Stderr.Fd()))
 	if err != nil {
@@ -48,11 +51,11 @@ func redirectOutput() (*os.File, func(), error) {
 		// however that can fail. Make a best attempt effort at reestablishing it
 		_ = unix.Dup2(stdout, int(os.Stdout.Fd()))
 		unix.Close(stdout)
-		return nil, nil, err
+		return nil, err
 	}
 	// stdout and stdin are now pointed to our file and
 	// the fds used for reestablishing them are close-on-exec
-	return tmpfile, dupAndClose(stdout, stderr), nil
+	return dupAndClose(stdout, stderr), nil
 }
 
 func dupAndClose(stdout, stderr int) func() {
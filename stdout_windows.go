@@ -1,5 +1,78 @@
-// +build windows
+// +build windows,!sussmemout
 
 package suss
 
-var _ = UNIMPLEMENTED
+import (
+	"io/ioutil"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// redirectOutput redirects stdout and stderr into
+// a file so that printing during a test does not
+// spew to the terminal. This allows us to only
+// print the output from the final shrinked test
+//
+// Windows has no dup2, so instead we point the process
+// standard handles at the file with SetStdHandle and
+// swap the os.Stdout and os.Stderr variables, which
+// the Go runtime opened at startup and would keep
+// writing to the console otherwise.
+func redirectOutput(b *buffer) (func(), error) {
+	tmpfile, err := ioutil.TempFile("", "suss")
+	if err != nil {
+		return nil, err
+	}
+	defer tmpfile.Close()
+	b.stdout = tmpfile.Name()
+
+	// os.Stdout and os.Stderr get their own handles to the
+	// file, so that closing one does not close the other
+	stdout, err := dupFile(tmpfile)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := dupFile(tmpfile)
+	if err != nil {
+		stdout.Close()
+		return nil, err
+	}
+	origStdout, origStderr := os.Stdout, os.Stderr
+	// GetStdHandle can't fail for handles that we know exist,
+	// and if SetStdHandle fails, output from the test
+	// will still show up, just not redirected.
+	outh, _ := windows.GetStdHandle(windows.STD_OUTPUT_HANDLE)
+	errh, _ := windows.GetStdHandle(windows.STD_ERROR_HANDLE)
+	windows.SetStdHandle(windows.STD_OUTPUT_HANDLE, windows.Handle(stdout.Fd()))
+	windows.SetStdHandle(windows.STD_ERROR_HANDLE, windows.Handle(stderr.Fd()))
+	os.Stdout, os.Stderr = stdout, stderr
+
+	f := func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+		err := windows.SetStdHandle(windows.STD_OUTPUT_HANDLE, outh)
+		if err != nil {
+			panic(err)
+		}
+		err = windows.SetStdHandle(windows.STD_ERROR_HANDLE, errh)
+		if err != nil {
+			panic(err)
+		}
+		stdout.Close()
+		stderr.Close()
+	}
+	return f, nil
+}
+
+// dupFile returns a new file for a duplicate of f's handle.
+// The duplicate is not inheritable, so that it doesn't
+// leak into processes started by a test.
+func dupFile(f *os.File) (*os.File, error) {
+	proc := windows.CurrentProcess()
+	var h windows.Handle
+	err := windows.DuplicateHandle(proc, windows.Handle(f.Fd()), proc, &h, 0, false, windows.DUPLICATE_SAME_ACCESS)
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(h), f.Name()), nil
+}
@@ -0,0 +1,252 @@
+package suss
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// runeSegment is the runes lo, lo+stride, lo+2*stride... up to hi.
+type runeSegment struct {
+	lo, hi, stride rune
+}
+
+func (s runeSegment) size() uint64 {
+	return uint64((s.hi-s.lo)/s.stride) + 1
+}
+
+// runeSet is an ordered set of runes. The order
+// decides how runes shrink: earlier runes are simpler.
+type runeSet struct {
+	alphabet []rune
+	segments []runeSegment
+	n        uint64
+}
+
+// defaultSegments is every valid rune, ordered so that
+// ASCII comes first, starting at '0'.
+var defaultSegments = []runeSegment{
+	{'0', 0x7f, 1},
+	{0, '0' - 1, 1},
+	{0x80, 0xd7ff, 1},
+	// skip surrogates, they can't be encoded in UTF-8
+	{0xe000, unicode.MaxRune, 1},
+}
+
+func newRuneSet(alphabet []rune, tables []*unicode.RangeTable) *runeSet {
+	rs := &runeSet{}
+	switch {
+	case len(alphabet) > 0:
+		for _, c := range alphabet {
+			if !utf8.ValidRune(c) {
+				panic("invalid rune in alphabet")
+			}
+		}
+		rs.alphabet = alphabet
+		rs.n = uint64(len(alphabet))
+		return rs
+	case len(tables) > 0:
+		for _, t := range tables {
+			for _, r := range t.R16 {
+				rs.addSegment(runeSegment{rune(r.Lo), rune(r.Hi), rune(r.Stride)})
+			}
+			for _, r := range t.R32 {
+				rs.addSegment(runeSegment{rune(r.Lo), rune(r.Hi), rune(r.Stride)})
+			}
+		}
+		if rs.n == 0 {
+			panic("no valid runes in tables")
+		}
+	default:
+		rs.segments = defaultSegments
+		for _, s := range rs.segments {
+			rs.n += s.size()
+		}
+	}
+	return rs
+}
+
+// addSegment adds a segment to the set, leaving out surrogates.
+func (rs *runeSet) addSegment(s runeSegment) {
+	const surrLo, surrHi = 0xd800, 0xdfff
+	if s.hi < surrLo || s.lo > surrHi {
+		rs.segments = append(rs.segments, s)
+		rs.n += s.size()
+		return
+	}
+	if s.lo < surrLo {
+		// last element below the surrogates
+		hi := s.lo + (surrLo-1-s.lo)/s.stride*s.stride
+		rs.addSegment(runeSegment{s.lo, hi, s.stride})
+	}
+	if s.hi > surrHi {
+		// first element above the surrogates
+		lo := s.lo
+		if lo <= surrHi {
+			lo += ((surrHi-lo)/s.stride + 1) * s.stride
+		}
+		if lo <= s.hi {
+			rs.addSegment(runeSegment{lo, s.hi, s.stride})
+		}
+	}
+}
+
+func (rs *runeSet) rune(k uint64) rune {
+	if rs.alphabet != nil {
+		return rs.alphabet[k]
+	}
+	for _, s := range rs.segments {
+		n := s.size()
+		if k < n {
+			return s.lo + rune(k)*s.stride
+		}
+		k -= n
+	}
+	panic("rune index out of range")
+}
+
+// index returns the index of c in the set.
+func (rs *runeSet) index(c rune) (uint64, bool) {
+	if rs.alphabet != nil {
+		for i, a := range rs.alphabet {
+			if a == c {
+				return uint64(i), true
+			}
+		}
+		return 0, false
+	}
+	k := uint64(0)
+	for _, s := range rs.segments {
+		if c >= s.lo && c <= s.hi && (c-s.lo)%s.stride == 0 {
+			return k + uint64((c-s.lo)/s.stride), true
+		}
+		k += s.size()
+	}
+	return 0, false
+}
+
+// nastyRunes are runes that tend to break code handling text.
+var nastyRunes = []rune{
+	0,        // NUL
+	0x7f,     // DEL
+	0x85,     // next line
+	0x301,    // combining acute accent
+	0x130,    // capital I with dot, changes length when lowercased
+	0xdf,     // sharp s, uppercases to two runes
+	0x5d0,    // hebrew alef, right-to-left
+	0x200b,   // zero width space
+	0x200f,   // right-to-left mark
+	0x202e,   // right-to-left override
+	0x2028,   // line separator
+	0xd7ff,   // last rune before the surrogates
+	0xe000,   // first rune after the surrogates
+	0xfeff,   // byte order mark
+	0xfffd,   // replacement character
+	0xffff,   // noncharacter
+	0x1f600,  // emoji, 4 bytes in UTF-8
+	0x10ffff, // highest code point
+}
+
+func (rs *runeSet) sample(r *rand.Rand) uint64 {
+	if r.Intn(3) == 0 {
+		c := nastyRunes[r.Intn(len(nastyRunes))]
+		if k, ok := rs.index(c); ok {
+			return k
+		}
+	}
+	if r.Intn(2) == 0 {
+		// ASCII is the most common input, even in
+		// unrestricted sets
+		for i := 0; i < 5; i++ {
+			k := uint64(r.Intn(utf8.RuneSelf))
+			if k < rs.n && rs.rune(k) < utf8.RuneSelf {
+				return k
+			}
+		}
+	}
+	return uint64(r.Int63n(int64(rs.n)))
+}
+
+func (rs *runeSet) draw(d Data) rune {
+	return rs.rune(drawIndex(d, rs.n, rs.sample))
+}
+
+// RuneGen generates a valid unicode code point.
+// Runes shrink towards ASCII, starting at '0'.
+// After Fill, the value can be read from Value.
+type RuneGen struct {
+	Value rune
+
+	// Alphabet, if not empty, restricts the generated runes
+	// to the runes in it. Runes shrink towards the start
+	// of the Alphabet.
+	Alphabet []rune
+
+	// Tables, if not empty and Alphabet is empty, restricts
+	// the generated runes to the ones in the tables.
+	// Surrogates are never generated.
+	Tables []*unicode.RangeTable
+}
+
+func (g *RuneGen) Fill(d Data) {
+	g.Value = newRuneSet(g.Alphabet, g.Tables).draw(d)
+}
+
+// Rune is a convenience function that returns
+// a rune from the Runner.
+func (r *Runner) Rune() rune {
+	var g RuneGen
+	r.Draw(&g)
+	return g.Value
+}
+
+// StringGen generates a valid UTF-8 string.
+// It is built on SliceGen, so strings shrink by deleting
+// runes, and then by shrinking the remaining runes towards ASCII.
+// After Fill, the value can be read from Value.
+type StringGen struct {
+	Value string
+
+	// Avg, Min and Max control the length of the string,
+	// in runes. A zero Avg means an average of 10 runes
+	// and a zero Max means there is no maximum.
+	Avg int
+	Min int
+	Max int
+
+	// Alphabet and Tables restrict the runes used,
+	// as they do for RuneGen.
+	Alphabet []rune
+	Tables   []*unicode.RangeTable
+}
+
+func (g *StringGen) Fill(d Data) {
+	rs := newRuneSet(g.Alphabet, g.Tables)
+	var b strings.Builder
+	sl := Slice(func() {
+		b.WriteRune(rs.draw(d))
+	})
+	if g.Avg != 0 {
+		sl.Avg = g.Avg
+	} else {
+		sl.Avg = 10
+	}
+	sl.Min = g.Min
+	if g.Max != 0 {
+		sl.Max = g.Max
+	}
+	sl.Fill(d)
+	g.Value = b.String()
+}
+
+// String is a convenience function that returns
+// a valid UTF-8 string from the Runner.
+//
+// Note that this makes Runner a fmt.Stringer, so printing
+// a Runner with the fmt package draws a string.
+func (r *Runner) String() string {
+	var g StringGen
+	r.Draw(&g)
+	return g.Value
+}
@@ -0,0 +1,47 @@
+package suss
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func TestStringValid(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		str := s.String()
+		if !utf8.ValidString(str) {
+			s.Fatalf("invalid UTF-8: %q", str)
+		}
+	})
+}
+
+func TestStringRestricted(t *testing.T) {
+	// a table with a stride and a range crossing the surrogates
+	table := &unicode.RangeTable{
+		R16: []unicode.Range16{
+			{Lo: 'a', Hi: 'k', Stride: 2},
+			{Lo: 0xd7f0, Hi: 0xe010, Stride: 3},
+		},
+	}
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		g := StringGen{Tables: []*unicode.RangeTable{table}, Min: 1, Max: 5}
+		s.Draw(&g)
+		n := utf8.RuneCountInString(g.Value)
+		if n < 1 || n > 5 {
+			s.Fatalf("string %q has %v runes, want between 1 and 5", g.Value, n)
+		}
+		for _, c := range g.Value {
+			if !unicode.Is(table, c) || unicode.Is(unicode.Cs, c) {
+				s.Fatalf("rune %U not in table", c)
+			}
+		}
+		a := StringGen{Alphabet: []rune("xyz")}
+		s.Draw(&a)
+		if strings.Trim(a.Value, "xyz") != "" {
+			s.Fatalf("string %q not in alphabet", a.Value)
+		}
+	})
+}
@@ -10,11 +10,15 @@ package suss
 
 import (
 	"bytes"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"math/rand"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -23,36 +27,94 @@ import (
 type Runner struct {
 	rnd     *rand.Rand
 	seeder  *rand.Rand
+	seed    int64
 	t       *testing.T
 	buf     *buffer
 	lastBuf *buffer
 	tree    *bufTree
 
+	settings Settings
+
+	db        *exampleDB
+	dbExample []byte
+	replay    []byte
+
 	testfunc  func()
 	startTime time.Time
 
-	change int
+	change        int
+	shrinkTimeout bool
+
+	// examples that found new coverage, for GuideCoverage
+	corpus          []*buffer
+	coverage        float64
+	corpusMutations int
+
+	// the best scoring example for every target label
+	targets      map[string]*buffer
+	targetScores map[string]float64
+
+	valid int
+	stats Stats
 }
 
 // NewTest returns a Runner that runs a suspicion test.
+//
+// The random data is seeded from the -suss.seed test flag or
+// the SUSS_SEED environment variable if either is set and from
+// the current time otherwise. The seed is printed when a test fails.
+//
+// Failing examples are saved in testdata/suss/<TestName> in the
+// package directory and replayed by later runs. Commit the directory
+// to share the examples, or add it to .gitignore to keep them local.
+//
+// The Runner uses the settings profile named by the -suss.profile
+// test flag or the SUSS_PROFILE environment variable, or the
+// "default" profile if neither is set. See RegisterProfile.
 func NewTest(t *testing.T) *Runner {
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := newRunner(t, newExampleDB(t.Name()))
+	if *replayFlag != "" {
+		r.Replay(*replayFlag)
+	}
+	return r
+}
+
+func newRunner(t *testing.T, db *exampleDB) *Runner {
+	seed, err := defaultSeed()
+	if err != nil {
+		t.Fatalf("suss: %v", err)
+	}
+	settings, err := defaultProfile()
+	if err != nil {
+		t.Fatalf("suss: %v", err)
+	}
 	r := &Runner{
-		t:       t,
-		seeder:  rnd,
-		lastBuf: &buffer{},
-		tree:    newBufTree(),
+		t:        t,
+		settings: settings,
+		lastBuf:  &buffer{},
+		tree:     newBufTree(),
+		db:       db,
 	}
+	r.Seed(seed)
 	return r
 }
 
+// Seed sets the seed used to generate data. Running the same
+// test with the same seed generates and shrinks the same
+// sequence of examples, as long as the test is deterministic
+// and does not run out of time before finding a failure.
+//
+// Seed must be called before Run.
+func (r *Runner) Seed(seed int64) {
+	r.seed = seed
+	r.seeder = rand.New(rand.NewSource(seed))
+}
+
 func (r *Runner) newData() {
 	r.rnd = rand.New(rand.NewSource(int64(r.seeder.Uint64())))
-	r.buf = newBuffer(maxsize, r.regularDraw)
+	r.buf = newBuffer(r.settings.MaxSize, r.regularDraw)
 }
 
-const maxsize = 8 << 10
-
 // Run is the main entry point to a suspicion test.
 // To run a suspicion test, give it a function that verifies some
 // property and calls Runner.Fatalf if it's violated.
@@ -72,22 +134,240 @@ const maxsize = 8 << 10
 func (r *Runner) Run(f func()) {
 	r.startTime = time.Now()
 	r.testfunc = f
+	if r.replay != nil {
+		r.runReplay()
+		return
+	}
+	if !r.replayExamples() {
+		r.generate()
+		if r.lastBuf.status != statusInteresting && len(r.targets) > 0 {
+			r.target()
+		}
+	}
+	r.stats.GenerateTime = time.Since(r.startTime)
+	r.logTargets()
+	// if we got here with an interesting buffer, that usually
+	// means a failing test, now try shrinking it
+	if r.lastBuf.status != statusInteresting {
+		if testing.Verbose() {
+			r.logStats()
+		}
+		return
+	}
+	r.reportFailure()
+	if testing.Verbose() {
+		r.logStats()
+	}
+	r.logSeed()
+	r.t.FailNow()
+}
+
+// logSeed reports the seed of a failing test,
+// so that the failure can be reproduced.
+func (r *Runner) logSeed() {
+	r.t.Logf("suss: seed %d, rerun with -suss.seed=%d", r.seed, r.seed)
+}
+
+// reportFailure shrinks the interesting buffer in lastBuf,
+// saves it in the database and reports the output of
+// the shrunk example.
+func (r *Runner) reportFailure() {
+	r.checkDeterministic()
+	r.lastBuf.finalize()
+	r.shrink()
+	r.stats.ShrinkTime = time.Since(r.startTime)
+	r.checkDeterministic()
+	if r.shrinkTimeout {
+		r.t.Logf("suss: shrinking stopped after %v, the example may not be minimal", r.settings.ShrinkTime)
+	}
+	if r.dbExample != nil && !bytes.Equal(r.dbExample, r.lastBuf.buf) {
+		// we shrunk a stored example further, the
+		// old one is redundant now
+		if err := r.db.delete(r.dbExample); err != nil {
+			r.t.Logf("suss: could not delete example: %v", err)
+		}
+	}
+	if err := r.db.save(r.lastBuf.buf); err != nil {
+		r.t.Logf("suss: could not save example: %v", err)
+	}
+	r.printOutput(r.lastBuf)
+	r.t.Logf("suss: replay this example with -suss.replay=%s", encodeExample(r.lastBuf.buf))
+}
+
+// printOutput reports the output captured while running
+// the buffer. Redirected output is dumped on stdout and
+// the log is reported to the test.
+func (r *Runner) printOutput(b *buffer) {
+	if b.stdout != "" {
+		stdfile, err := os.Open(b.stdout)
+		if err != nil {
+			fmt.Printf("could not open stdout: %v\n", err)
+		} else {
+			io.Copy(os.Stdout, stdfile)
+			stdfile.Close()
+		}
+		os.Remove(b.stdout)
+	}
+	if b.stdoutMem.Len() > 0 {
+		os.Stdout.Write(b.stdoutMem.Bytes())
+	}
+	if b.log.Len() > 0 {
+		r.t.Log(strings.TrimSuffix(b.log.String(), "\n"))
+	}
+}
+
+// Replay makes Run execute the test function exactly once,
+// using the example printed by a previous failure instead
+// of generating data. No shrinking is done, which makes it
+// a convenient way to step through a failure in a debugger.
+//
+// The -suss.replay test flag calls Replay for every test
+// created with NewTest, so it is usually combined with -run.
+// It returns the Runner so that it can be chained with NewTest.
+func (r *Runner) Replay(encoded string) *Runner {
+	byt, err := decodeExample(encoded)
+	if err != nil {
+		r.t.Fatalf("suss: invalid example %q: %v", encoded, err)
+	}
+	r.replay = byt
+	return r
+}
+
+func (r *Runner) runReplay() {
+	r.buf = bufFromBytes(r.replay)
+	r.runOnce()
+	r.stats.record(r.buf)
+	r.printOutput(r.buf)
+	switch r.buf.status {
+	case statusInteresting:
+		r.t.FailNow()
+	case statusOverrun:
+		r.t.Logf("suss: replayed example did not fail, the test drew more data than the example has")
+	case statusInvalid:
+		r.t.Logf("suss: replayed example did not fail, the test marked it invalid")
+	default:
+		r.t.Logf("suss: replayed example did not fail")
+	}
+}
+
+// emptyExample is the encoding of the empty example. It is
+// outside the base64 alphabet, and makes sure that the empty
+// example isn't mistaken for an unset -suss.replay flag.
+const emptyExample = "."
+
+// encodeExample encodes the bytes of a buffer in a form that
+// can be printed and passed on the command line.
+func encodeExample(byt []byte) string {
+	if len(byt) == 0 {
+		return emptyExample
+	}
+	return base64.RawURLEncoding.EncodeToString(byt)
+}
+
+func decodeExample(s string) ([]byte, error) {
+	switch s {
+	case emptyExample:
+		return []byte{}, nil
+	case "":
+		return nil, errors.New("no example")
+	}
+	return base64.RawURLEncoding.DecodeString(s)
+}
+
+// replayExamples runs the test against every example in the
+// database. The first example that still fails becomes the
+// starting point for shrinking. Examples that no longer fail
+// are evicted from the database.
+//
+// If the test has changed to draw more data than an example has,
+// the example is replayed again with the simplest data, zeros,
+// after it. Only if that doesn't fail either is it evicted.
+// When it does fail, it is shrunk and replaces the old example.
+func (r *Runner) replayExamples() bool {
+	examples, err := r.db.fetch()
+	if err != nil {
+		r.t.Logf("suss: could not read examples: %v", err)
+	}
+	for _, byt := range examples {
+		r.runExample(byt)
+		if r.buf.status == statusInteresting {
+			r.lastBuf = r.buf
+			r.dbExample = byt
+			return true
+		}
+		r.buf.discard()
+		if err := r.db.delete(byt); err != nil {
+			r.t.Logf("suss: could not delete example: %v", err)
+		}
+	}
+	return false
+}
+
+// runExample runs the test against the bytes in byt. If the
+// test draws more data than byt has, it is run again with
+// zeros after byt.
+func (r *Runner) runExample(byt []byte) {
+	r.buf = bufFromBytes(byt)
+	r.runOnce()
+	r.stats.record(r.buf)
+	r.tree.add(r.buf)
+	if r.buf.status == statusOverrun && len(byt) < r.settings.MaxSize {
+		padded := make([]byte, r.settings.MaxSize)
+		copy(padded, byt)
+		r.buf.discard()
+		r.buf = bufFromBytes(padded)
+		r.runOnce()
+		r.stats.record(r.buf)
+		r.tree.add(r.buf)
+	}
+}
+
+// generate runs the test with random data until it finds an
+// interesting buffer or runs out of examples or time.
+func (r *Runner) generate() {
 	r.newData()
+	guided := r.settings.Guidance == GuideCoverage && testing.CoverMode() != ""
+	if guided {
+		r.coverage = testing.Coverage()
+	}
 	mutations := 0
 	for !r.tree.dead[0] {
 		r.runOnce()
+		r.stats.record(r.buf)
+		r.checkHealth()
 		r.tree.add(r.buf)
 		if r.buf.status == statusInteresting {
 			r.lastBuf = r.buf
-			break
+			return
+		}
+		if r.buf.status == statusValid {
+			r.valid++
+			r.updateTargets(r.buf)
+		}
+		if guided && r.buf.status != statusOverrun {
+			// coverage only goes up when the
+			// example ran code that no example
+			// before it did
+			if c := testing.Coverage(); c > r.coverage {
+				r.coverage = c
+				r.corpus = append(r.corpus, r.buf)
+			}
 		}
-		if time.Since(r.startTime) > 1*time.Second {
+		if r.generateDone(1) || len(r.targets) > 0 && r.generateDone(2) {
+			// when the test has targets, half of the
+			// budget is saved for hill climbing
 			r.buf.discard()
 			return
 		}
-		if mutations >= 10 {
+		if mutations >= r.settings.Mutations {
 			r.buf.discard()
-			r.newData()
+			if len(r.corpus) > 0 && r.seeder.Intn(2) == 0 {
+				r.lastBuf = r.corpus[r.seeder.Intn(len(r.corpus))]
+				r.corpusMutations++
+				r.buf = newBuffer(r.settings.MaxSize, r.corpusMutator())
+			} else {
+				r.newData()
+			}
 			mutations = 0
 			continue
 		}
@@ -99,25 +379,112 @@ func (r *Runner) Run(f func()) {
 		// can discard its stdout regardless
 		r.buf.discard()
 		mut := r.newMutator()
-		r.buf = newBuffer(maxsize, mut)
+		r.buf = newBuffer(r.settings.MaxSize, mut)
 	}
-	// if we got here, that means that we have an interestinr.buffer
-	// That usually means a failing test, now try shrinking it
-	if r.buf.status != statusInteresting {
+}
+
+// generateDone reports whether 1/div of the budget for
+// generating examples has been spent.
+func (r *Runner) generateDone(div int) bool {
+	return r.valid >= r.settings.MaxExamples/div || time.Since(r.startTime) > r.settings.GenerateTime/time.Duration(div)
+}
+
+// Target records a score for the current example. While looking
+// for a failing example, the examples with the highest score for
+// each label are mutated to find examples with even higher scores.
+// This helps finding failures that only happen when some measure,
+// like the length of a queue, is pushed to an extreme.
+//
+// If Target is called several times with the same label in one
+// example, the last score is used. The highest score found for each
+// label is logged when the test finishes.
+func (r *Runner) Target(label string, score float64) {
+	if math.IsNaN(score) {
+		panic("suss: NaN target score")
+	}
+	if r.buf.targets == nil {
+		r.buf.targets = make(map[string]float64)
+	}
+	r.buf.targets[label] = score
+}
+
+func (r *Runner) updateTargets(b *buffer) {
+	for label, score := range b.targets {
+		if best, ok := r.targetScores[label]; ok && score <= best {
+			continue
+		}
+		if r.targets == nil {
+			r.targets = make(map[string]*buffer)
+			r.targetScores = make(map[string]float64)
+		}
+		r.targets[label] = b
+		r.targetScores[label] = score
+	}
+}
+
+// target hill climbs on the best example for every label,
+// by making small mutations and keeping those that
+// improve the score.
+func (r *Runner) target() {
+	labels := make([]string, 0, len(r.targets))
+	for label := range r.targets {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for !r.tree.dead[0] && !r.generateDone(1) {
+		label := labels[r.seeder.Intn(len(labels))]
+		r.lastBuf = r.targets[label]
+		r.buf = newBuffer(r.settings.MaxSize, r.targetMutator())
+		r.runOnce()
+		r.stats.record(r.buf)
+		r.tree.add(r.buf)
+		if r.buf.status == statusInteresting {
+			r.lastBuf = r.buf
+			return
+		}
+		if r.buf.status == statusValid {
+			r.valid++
+			r.updateTargets(r.buf)
+		}
 		r.buf.discard()
-		return
 	}
-	r.lastBuf.finalize()
-	r.shrink()
-	// ok, open the output file and dump it on stdout
-	stdfile, err := os.Open(r.lastBuf.stdout)
-	if err != nil {
-		fmt.Println("could not open stdout: %v\n", err)
+}
+
+// targetMutator returns a mutator that changes a couple of
+// the draws of the last buffer by a little bit and
+// keeps the rest of them.
+func (r *Runner) targetMutator() drawFunc {
+	mutateDraws := []drawFunc{
+		r.drawLarger,
+		r.drawSmaller,
+		r.flipBit,
+	}
+	p := 2 / float64(len(r.lastBuf.blocks)+1)
+	return func(b *buffer, n int, smp Sample) []byte {
+		var res []byte
+		switch {
+		case b.index+n > len(r.lastBuf.buf):
+			res = smp(r.rnd, n)
+		case r.rnd.Float64() < p:
+			d := r.seeder.Intn(len(mutateDraws))
+			res = mutateDraws[d](b, n, smp)
+		default:
+			res = r.drawExisting(b, n, smp)
+		}
+		return r.rewriteNovelty(b, res)
+	}
+}
+
+// logTargets logs the highest score found for every label.
+func (r *Runner) logTargets() {
+	labels := make([]string, 0, len(r.targetScores))
+	for label := range r.targetScores {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		r.t.Logf("suss: highest score for target %q: %v", label, r.targetScores[label])
 	}
-	io.Copy(os.Stdout, stdfile)
-	stdfile.Close()
-	os.Remove(r.lastBuf.stdout)
-	r.t.FailNow()
 }
 
 func (r *Runner) shrink() {
@@ -222,7 +589,15 @@ func (r *Runner) shrink() {
 					delete(blocks, k)
 				}
 			}
-			for k, s := range blocks {
+			// iterate in a fixed order, so that shrinking
+			// is reproducible from the seed
+			keys := make([]string, 0, len(blocks))
+			for k := range blocks {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				s := blocks[k]
 				minimize([]byte(k), func(b []byte) bool {
 					for _, v := range s {
 						copy(buf[v[0]:v[1]], b)
@@ -328,15 +703,15 @@ func (r *Runner) runOnce() {
 			r.buf.status = statusInvalid
 			return
 		}
-		panic(r)
+		panic(rec)
 	}()
-	f, closefunc, err := redirectOutput()
-	if err != nil {
-		panic("could not redirect output:" + err.Error())
+	if r.settings.Capture == CaptureStdout {
+		closefunc, err := redirect(r.buf)
+		if err != nil {
+			panic("could not redirect output:" + err.Error())
+		}
+		defer closefunc()
 	}
-	defer closefunc()
-	r.buf.stdout = f.Name()
-	f.Close()
 	r.testfunc()
 	r.buf.status = statusValid
 	testfail = false
@@ -346,6 +721,12 @@ func (r *Runner) tryShrink(byt []byte) bool {
 	if r.lastBuf.status != statusInteresting {
 		panic("whoa")
 	}
+	if time.Since(r.startTime) > r.settings.ShrinkTime {
+		// out of time, every remaining attempt fails
+		// so that the shrink passes finish quickly
+		r.shrinkTimeout = true
+		return false
+	}
 	s := r.lastBuf.index
 	if len(byt) > s {
 		byt = byt[:s]
@@ -370,6 +751,7 @@ func (r *Runner) tryShrink(byt []byte) bool {
 
 	r.buf = bufFromBytes(byt)
 	r.runOnce()
+	r.stats.Shrinks++
 	r.tree.add(r.buf)
 	r.buf.finalize()
 	if r.considerNewBuffer(r.buf) {
@@ -428,12 +810,28 @@ func (r *Runner) zeroBlocks() {
 // It takes a fmt.Printf format string that is printed
 // when a minimal failing example has been found.
 func (r *Runner) Fatalf(format string, i ...interface{}) {
-	// TODO: make this hook into the shrinking and gofuzz
-	fmt.Printf(format, i...)
-	fmt.Println()
+	r.Logf(format, i...)
 	panic(new(failed))
 }
 
+// Logf formats its arguments like fmt.Printf and records
+// the text as output of the current example. Like other
+// output, it is only shown for the minimal failing example.
+//
+// With CaptureLog, the text is kept with the example
+// instead of being printed to stdout, which makes Logf
+// the way to produce output from parallel tests.
+func (r *Runner) Logf(format string, i ...interface{}) {
+	var w io.Writer = os.Stdout
+	if r.settings.Capture == CaptureLog {
+		w = &r.buf.log
+	}
+	fmt.Fprintf(w, format, i...)
+	if !strings.HasSuffix(format, "\n") {
+		fmt.Fprintln(w)
+	}
+}
+
 // Draw takes a generator and fills it with data. This is
 // used to get the data that might cause a failing example.
 func (r *Runner) Draw(g Generator) {
@@ -566,6 +964,20 @@ func (r *Runner) newMutator() drawFunc {
 
 }
 
+// corpusMutator returns a mutator for examples from the
+// corpus. Half of the draws keep the existing data, so that
+// the mutated example is likely to reach the same code as
+// the original and then explore from there.
+func (r *Runner) corpusMutator() drawFunc {
+	mut := r.newMutator()
+	return func(b *buffer, n int, smp Sample) []byte {
+		if b.index+n <= len(r.lastBuf.buf) && r.seeder.Intn(2) == 0 {
+			return r.rewriteNovelty(b, r.drawExisting(b, n, smp))
+		}
+		return mut(b, n, smp)
+	}
+}
+
 func (r *Runner) drawLarger(b *buffer, n int, smp Sample) []byte {
 	exist := r.lastBuf.buf[b.index : b.index+n]
 	sample := smp(r.rnd, n)
@@ -0,0 +1,224 @@
+package suss
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inSubprocess reports whether the test is running
+// in a process started by runSubprocess.
+func inSubprocess() bool {
+	return os.Getenv("SUSS_SUBPROCESS") == "1"
+}
+
+// runSubprocess runs the current test in a new process of
+// the test binary, so that tests can check how suss fails
+// a test. It returns the output of the process and whether
+// the test failed. The environment variables in env are
+// added to the ones of the process.
+func runSubprocess(t *testing.T, env ...string) (string, bool) {
+	t.Helper()
+	elems := strings.Split(t.Name(), "/")
+	for i, e := range elems {
+		elems[i] = "^" + e + "$"
+	}
+	return runTestSubprocess(t, []string{"-test.run=" + strings.Join(elems, "/")}, env...)
+}
+
+// runTestSubprocess is like runSubprocess, but runs the
+// test binary with the given flags instead.
+func runTestSubprocess(t *testing.T, flags []string, env ...string) (string, bool) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], append(flags, "-test.v")...)
+	cmd.Env = append(append(os.Environ(), "SUSS_SUBPROCESS=1"), env...)
+	out, err := cmd.CombinedOutput()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		t.Fatal(err)
+	}
+	return string(out), err != nil
+}
+
+// newTest is NewTest with the example database in a temporary
+// directory, so that failing tests don't leave examples behind.
+func newTest(t *testing.T) *Runner {
+	s := NewTest(t)
+	s.db = &exampleDB{dir: t.TempDir()}
+	return s
+}
+
+func TestTarget(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	best := math.Inf(-1)
+	s.Run(func() {
+		score := float64(s.IntRange(-1000, 1000))
+		s.Target("score", score)
+		best = math.Max(best, score)
+	})
+	if s.targetScores["score"] != best {
+		t.Fatalf("highest score = %v, want %v", s.targetScores["score"], best)
+	}
+}
+
+func TestStats(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 200})
+	empty := 0
+	s.Run(func() {
+		var l []bool
+		sl := Slice(func() {
+			l = append(l, s.Bool())
+		})
+		sl.Avg = 5
+		s.Draw(sl)
+		if len(l) == 0 {
+			empty++
+			s.Event("empty")
+		}
+		if len(l) > 5 {
+			Invalid()
+		}
+	})
+	st := s.Stats()
+	if st.Valid != 200 {
+		t.Errorf("valid = %d, want 200", st.Valid)
+	}
+	if st.Examples != st.Valid+st.Invalid+st.Overrun+st.Failing {
+		t.Errorf("examples don't add up: %+v", st)
+	}
+	if st.Events["empty"] != empty {
+		t.Errorf("empty events = %d, want %d", st.Events["empty"], empty)
+	}
+	if st.AvgSize <= 0 {
+		t.Errorf("average size = %v", st.AvgSize)
+	}
+}
+
+func TestEncodeExample(t *testing.T) {
+	for _, byt := range [][]byte{{}, {0}, {0xff, 0}, bytes.Repeat([]byte{1, 2, 3}, 10)} {
+		enc := encodeExample(byt)
+		if enc == "" {
+			t.Errorf("%v encodes as the empty string", byt)
+		}
+		dec, err := decodeExample(enc)
+		if err != nil || dec == nil || !bytes.Equal(dec, byt) {
+			t.Errorf("%v encodes as %q, which decodes as %v, %v", byt, enc, dec, err)
+		}
+	}
+	for _, s := range []string{"", "a", "ab!"} {
+		if _, err := decodeExample(s); err == nil {
+			t.Errorf("decoding %q didn't fail", s)
+		}
+	}
+}
+
+func TestReplay(t *testing.T) {
+	for _, byt := range [][]byte{{}, {5}, {5, 6}} {
+		runs := 0
+		var drawn []byte
+		s := newTest(t).Replay(encodeExample(byt))
+		s.Run(func() {
+			runs++
+			for range byt {
+				drawn = append(drawn, s.Byte())
+			}
+		})
+		if runs != 1 {
+			t.Fatalf("replaying %v ran %d examples, want 1", byt, runs)
+		}
+		if !bytes.Equal(drawn, byt) {
+			t.Fatalf("replaying %v drew %v", byt, drawn)
+		}
+	}
+}
+
+func TestReplayFailure(t *testing.T) {
+	if !inSubprocess() {
+		out, failed := runSubprocess(t)
+		if !failed {
+			t.Fatalf("replayed failure did not fail:\n%s", out)
+		}
+		if !strings.Contains(out, "drew 42\n") {
+			t.Fatalf("replayed example not shown:\n%s", out)
+		}
+		if n := strings.Count(out, "drew "); n != 1 {
+			t.Fatalf("%d examples shown, want 1:\n%s", n, out)
+		}
+		return
+	}
+	s := newTest(t).WithSettings(Settings{Capture: CaptureLog}).Replay(encodeExample([]byte{42}))
+	s.Run(func() {
+		v := s.Byte()
+		s.Logf("drew %d", v)
+		if v > 10 {
+			s.Fatalf("too big")
+		}
+	})
+}
+
+func TestSeedReproducible(t *testing.T) {
+	if !inSubprocess() {
+		out, failed := runSubprocess(t)
+		if !failed {
+			t.Fatalf("test did not fail:\n%s", out)
+		}
+		runs := regexp.MustCompile(`run \d: (.*)\n`).FindAllStringSubmatch(out, -1)
+		if len(runs) != 2 {
+			t.Fatalf("found %d runs, want 2:\n%s", len(runs), out)
+		}
+		if runs[0][1] != runs[1][1] {
+			t.Fatalf("runs with the same seed differ:\n%s\n%s", runs[0][1], runs[1][1])
+		}
+		return
+	}
+	for _, name := range []string{"1", "2"} {
+		t.Run(name, func(t *testing.T) {
+			s := newTest(t).WithSettings(Settings{
+				MaxExamples:  200,
+				GenerateTime: time.Minute,
+				ShrinkTime:   time.Minute,
+			})
+			s.Seed(42)
+			// every value drawn, in order, covers both
+			// generation and shrinking
+			trace := sha1.New()
+			t.Cleanup(func() {
+				t.Logf("run %s: %x %x", name, s.lastBuf.buf, trace.Sum(nil))
+			})
+			s.Run(func() {
+				var l []int
+				sl := Slice(func() {
+					l = append(l, s.IntRange(0, 1000))
+				})
+				s.Draw(sl)
+				fmt.Fprint(trace, l, ";")
+				sum := 0
+				for _, v := range l {
+					sum += v
+				}
+				if sum > 1000 {
+					s.Fatalf("sum too big: %v", l)
+				}
+			})
+		})
+	}
+}
+
+func TestPanicInRun(t *testing.T) {
+	defer func() {
+		if rec := recover(); rec != "boom" {
+			t.Fatalf("recovered %v, want the panic from the test function", rec)
+		}
+	}()
+	s := newTest(t)
+	s.Run(func() {
+		s.Byte()
+		panic("boom")
+	})
+}
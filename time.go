@@ -0,0 +1,248 @@
+package suss
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// sampleNasty returns a sample function for the range that
+// returns one of the nasty values in range half the time.
+// The nasty values are computed lazily, since finding
+// them can be expensive.
+func (ir intRange) sampleNasty(nasty func(r *rand.Rand) []int64) func(r *rand.Rand) uint64 {
+	return func(r *rand.Rand) uint64 {
+		if r.Intn(2) == 0 {
+			var in []int64
+			for _, v := range nasty(r) {
+				if v >= ir.lo && v <= ir.hi {
+					in = append(in, v)
+				}
+			}
+			if len(in) > 0 {
+				return ir.index(in[r.Intn(len(in))])
+			}
+		}
+		return ir.sample(r)
+	}
+}
+
+// DurationGen generates time.Duration values in the range
+// [Min, Max]. If both Min and Max are zero, the range is
+// every possible Duration.
+//
+// Durations shrink towards zero, or towards the bound
+// closest to zero if the range does not contain it.
+// After Fill, the value can be read from Value.
+type DurationGen struct {
+	Value time.Duration
+	Min   time.Duration
+	Max   time.Duration
+}
+
+var nastyDurations = []time.Duration{
+	1, time.Microsecond, time.Millisecond, time.Second, time.Minute, time.Hour,
+	// days around DST transitions
+	23 * time.Hour, 24 * time.Hour, 25 * time.Hour,
+	28 * 24 * time.Hour, 29 * 24 * time.Hour, 31 * 24 * time.Hour,
+	365 * 24 * time.Hour, 366 * 24 * time.Hour,
+	math.MaxInt32 * time.Second, (math.MaxInt32 + 1) * time.Second,
+	math.MaxInt64, math.MaxInt64 - 1, math.MinInt64, math.MinInt64 + 1,
+}
+
+func init() {
+	for _, d := range nastyDurations {
+		if d > 0 {
+			nastyDurations = append(nastyDurations, -d)
+		}
+	}
+}
+
+func (g *DurationGen) Fill(d Data) {
+	ir := intRange{int64(g.Min), int64(g.Max)}
+	if g.Min == 0 && g.Max == 0 {
+		ir = intRange{math.MinInt64, math.MaxInt64}
+	}
+	if ir.lo > ir.hi {
+		panic("invalid duration range")
+	}
+	smp := ir.sampleNasty(func(r *rand.Rand) []int64 {
+		nasty := make([]int64, len(nastyDurations))
+		for i, d := range nastyDurations {
+			nasty[i] = int64(d)
+		}
+		return nasty
+	})
+	g.Value = time.Duration(ir.value(drawIndex(d, ir.size(), smp)))
+}
+
+// Duration is a convenience function that returns
+// a time.Duration from the Runner.
+func (r *Runner) Duration() time.Duration {
+	var g DurationGen
+	r.Draw(&g)
+	return g.Value
+}
+
+// TimeGen generates time.Time values in the range [Min, Max].
+// A zero Min or Max means the earliest or latest time that can
+// be represented as nanoseconds since the Unix epoch, which are
+// in the years 1677 and 2262.
+//
+// Times shrink towards the Unix epoch, or towards the bound
+// closest to it if the range does not contain it.
+// After Fill, the value can be read from Value.
+type TimeGen struct {
+	Value time.Time
+	Min   time.Time
+	Max   time.Time
+
+	// Locations are the locations the time can be in.
+	// Times shrink towards the first location.
+	// If empty, all times are in UTC.
+	Locations []*time.Location
+
+	// Monotonic makes some of the times carry a monotonic
+	// clock reading, like the times returned by time.Now.
+	// This finds code that compares times with == or uses them
+	// as map keys, where the reading makes otherwise equal
+	// times differ. Times shrink towards not having a reading.
+	//
+	// Changing the location of a time strips the reading, so times
+	// with a reading are in time.Local. The reading depends on when
+	// the example runs, but the instant is always the same. Times
+	// too far from the present to carry a reading don't get one.
+	Monotonic bool
+}
+
+func (g *TimeGen) Fill(d Data) {
+	ir := intRange{math.MinInt64, math.MaxInt64}
+	if !g.Min.IsZero() {
+		ir.lo = unixNano(g.Min)
+	}
+	if !g.Max.IsZero() {
+		ir.hi = unixNano(g.Max)
+	}
+	if ir.lo > ir.hi {
+		panic("invalid time range")
+	}
+	loc := time.UTC
+	if len(g.Locations) > 0 {
+		loc = g.Locations[drawIndex(d, uint64(len(g.Locations)), nil)]
+	}
+	smp := ir.sampleNasty(func(r *rand.Rand) []int64 {
+		return nastyTimes(r, loc, ir)
+	})
+	ns := ir.value(drawIndex(d, ir.size(), smp))
+	t := time.Unix(0, ns).In(loc)
+	if g.Monotonic && drawIndex(d, 2, nil) == 1 {
+		if mt, ok := withMonotonic(t); ok {
+			t = mt
+		}
+	}
+	g.Value = t
+}
+
+// withMonotonic returns the instant t with a monotonic clock
+// reading. It reports false if the instant is too far from
+// the present for the reading to be kept.
+func withMonotonic(t time.Time) (time.Time, bool) {
+	now := time.Now()
+	diff := t.Sub(now)
+	if diff == math.MaxInt64 || diff == math.MinInt64 {
+		// Sub saturates, the instant would be wrong
+		return t, false
+	}
+	mt := now.Add(diff)
+	// Add strips the reading when it would overflow,
+	// and Round(0) always strips it
+	if !mt.Equal(t) || mt == mt.Round(0) {
+		return t, false
+	}
+	return mt, true
+}
+
+// unixNano is like t.UnixNano, but panics when
+// the time can't be represented
+func unixNano(t time.Time) int64 {
+	if t.Before(time.Unix(0, math.MinInt64)) || t.After(time.Unix(0, math.MaxInt64)) {
+		panic("time out of range: " + t.String())
+	}
+	return t.UnixNano()
+}
+
+// nastyTimes returns instants that commonly break code
+// dealing with time: the epoch, leap days, month and year ends,
+// the 2038 problem and daylight saving time transitions.
+// The year for calendar based instants is random, within
+// the range.
+func nastyTimes(r *rand.Rand, loc *time.Location, ir intRange) []int64 {
+	times := []time.Time{
+		time.Unix(0, 0),
+		time.Unix(math.MaxInt32, 0),
+		time.Unix(math.MaxInt32+1, 0),
+		time.Unix(math.MinInt32, 0),
+	}
+	lo, hi := time.Unix(0, ir.lo).In(loc).Year(), time.Unix(0, ir.hi).In(loc).Year()
+	year := lo + r.Intn(hi-lo+1)
+	leap := year - year%4
+	month := time.Month(1 + r.Intn(12))
+	times = append(times,
+		// leap days, including 2000 which is a leap year
+		// despite being divisible by 100
+		time.Date(leap, time.February, 29, 0, 0, 0, 0, loc),
+		time.Date(2000, time.February, 29, 12, 0, 0, 0, loc),
+		time.Date(leap, time.March, 1, 0, 0, 0, 0, loc),
+		// end of the year and of a month
+		time.Date(year+1, time.January, 1, 0, 0, 0, 0, loc),
+		time.Date(year, month+1, 1, 0, 0, 0, 0, loc),
+	)
+	times = append(times, zoneTransitions(loc, year)...)
+	var nasty []int64
+	for _, t := range times {
+		if t.Year() < 1678 || t.Year() > 2261 {
+			continue
+		}
+		ns := t.UnixNano()
+		// the instant itself and the ones right next to it
+		nasty = append(nasty, ns, ns-1, ns+1)
+	}
+	return nasty
+}
+
+// zoneTransitions returns the instants in the year where
+// loc changes its offset from UTC, and the instants an hour
+// on either side of them.
+func zoneTransitions(loc *time.Location, year int) []time.Time {
+	var trans []time.Time
+	const step = 7 * 24 * time.Hour
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+	for t := start; t.Before(end); t = t.Add(step) {
+		_, off := t.Zone()
+		next := t.Add(step)
+		if _, nextoff := next.Zone(); nextoff == off {
+			continue
+		}
+		// binary search for the first instant with the new offset
+		lo, hi := t, next
+		for hi.Sub(lo) > 1 {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, midoff := mid.Zone(); midoff == off {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		trans = append(trans, hi, hi.Add(-time.Hour), hi.Add(time.Hour))
+	}
+	return trans
+}
+
+// Time is a convenience function that returns
+// a time.Time in UTC from the Runner.
+func (r *Runner) Time() time.Time {
+	var g TimeGen
+	r.Draw(&g)
+	return g.Value
+}
@@ -0,0 +1,110 @@
+package suss
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeRange(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		lo, hi := s.Time(), s.Time()
+		if lo.After(hi) {
+			lo, hi = hi, lo
+		}
+		g := TimeGen{Min: lo, Max: hi, Locations: []*time.Location{time.UTC, time.FixedZone("X", 3600)}}
+		s.Draw(&g)
+		if g.Value.Before(lo) || g.Value.After(hi) {
+			s.Fatalf("time %v outside [%v, %v]", g.Value, lo, hi)
+		}
+	})
+}
+
+func TestDurationRange(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	s.Run(func() {
+		lo, hi := s.Duration(), s.Duration()
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if lo == 0 && hi == 0 {
+			// means the full range
+			Invalid()
+		}
+		g := DurationGen{Min: lo, Max: hi}
+		s.Draw(&g)
+		if g.Value < lo || g.Value > hi {
+			s.Fatalf("duration %v outside [%v, %v]", g.Value, lo, hi)
+		}
+	})
+}
+
+func TestTimeSimplest(t *testing.T) {
+	var g TimeGen
+	g.Fill(bufFromBytes(make([]byte, 10)))
+	if !g.Value.Equal(time.Unix(0, 0)) {
+		t.Errorf("simplest time = %v, want epoch", g.Value)
+	}
+	min := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	g = TimeGen{Min: min}
+	g.Fill(bufFromBytes(make([]byte, 10)))
+	if !g.Value.Equal(min) {
+		t.Errorf("simplest time = %v, want %v", g.Value, min)
+	}
+	var dg DurationGen
+	dg.Fill(bufFromBytes(make([]byte, 10)))
+	if dg.Value != 0 {
+		t.Errorf("simplest duration = %v, want 0", dg.Value)
+	}
+}
+
+func TestZoneTransitions(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		t.Skip(err)
+	}
+	trans := zoneTransitions(loc, 2020)
+	want := []time.Time{
+		time.Date(2020, time.March, 29, 1, 0, 0, 0, time.UTC),
+		time.Date(2020, time.October, 25, 1, 0, 0, 0, time.UTC),
+	}
+	if len(trans) != 3*len(want) {
+		t.Fatalf("got %d transition instants, want %d", len(trans), 3*len(want))
+	}
+	for i, w := range want {
+		if !trans[3*i].Equal(w) {
+			t.Errorf("transition %d = %v, want %v", i, trans[3*i], w)
+		}
+	}
+}
+
+func TestTimeMonotonic(t *testing.T) {
+	s := newTest(t).WithSettings(Settings{MaxExamples: 500})
+	readings := 0
+	s.Run(func() {
+		lo, hi := s.Time(), s.Time()
+		if lo.After(hi) {
+			lo, hi = hi, lo
+		}
+		g := TimeGen{Min: lo, Max: hi, Monotonic: true}
+		s.Draw(&g)
+		if g.Value.Before(lo) || g.Value.After(hi) {
+			s.Fatalf("time %v outside [%v, %v]", g.Value, lo, hi)
+		}
+		if g.Value != g.Value.Round(0) {
+			readings++
+		}
+	})
+	if readings == 0 {
+		t.Error("no time had a monotonic reading")
+	}
+	// far from the present, no reading can be attached
+	max := time.Date(1700, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		g := TimeGen{Max: max, Monotonic: true}
+		g.Fill(bufFromBytes([]byte{0, 0, 0, 0, 0, 0, 0, byte(i), 1}))
+		if g.Value.After(max) {
+			t.Fatalf("time %v after %v", g.Value, max)
+		}
+	}
+}